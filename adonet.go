@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"net"
+	"strings"
+)
+
+var adoNetAliases = map[string]string{
+	"server":          keyHost,
+	"data source":     keyHost,
+	"address":         keyHost,
+	"addr":            keyHost,
+	"network address": keyHost,
+	"host":            keyHost,
+	"port":            keyPort,
+	"database":        keyDatabase,
+	"initial catalog": keyDatabase,
+	"user id":         keyUsername,
+	"uid":             keyUsername,
+	"user":            keyUsername,
+	"username":        keyUsername,
+	"password":        keyPassword,
+	"pwd":             keyPassword,
+}
+
+// keys that only show up in ADO.NET strings, and make the format a safe bet
+var adoNetMarkers = []string{
+	"server", "data source", "initial catalog", "user id", "integrated security", "trusted_connection",
+	"trustservercertificate", "persist security info", "multipleactiveresultsets", "application name",
+}
+
+func (p *parser) adoNetParser() *parser {
+	return p.with(WithDelimiter(';'), WithQuotes('"', '\''), WithEscape(0), WithCollapseSpace(true), WithTrimValues(true), WithKeyValueSeparator("="))
+}
+
+func detectADONET(p *parser, input string) float64 {
+	if !strings.Contains(input, "=") {
+		return 0
+	}
+
+	// a single pair such as Server=db has no ";", and is less of a sure thing
+	confidence := 0.85
+	if !strings.Contains(input, ";") {
+		if strings.Count(input, "=") > 1 {
+			return 0
+		}
+
+		confidence = 0.6
+	}
+
+	for _, column := range strings.Split(input, ";") {
+		key, _, _ := strings.Cut(column, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		for _, marker := range adoNetMarkers {
+			if key == marker {
+				return confidence
+			}
+		}
+	}
+
+	return 0
+}
+
+func (p *parser) fromADONET(input string) (*connection, error) {
+	fields, err := p.adoNetParser().lexPairs(input)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &connection{}
+	for _, field := range fields {
+		key, value, _ := field.split("=")
+		value.segments = trimSegments(value.segments)
+
+		raw := strings.TrimSpace(key.text())
+		if raw == "" {
+			continue
+		}
+
+		if _, err = p.assignRaw(c, adoNetKey(raw), raw, value.text()); err != nil {
+			return nil, &SyntaxError{Offset: field.start, Message: err.Error()}
+		}
+	}
+
+	splitSqlServerHost(c)
+
+	return c, nil
+}
+
+// adoNetKey maps an ADO.NET key, which is case-insensitive, to the key it is
+// stored under.
+func adoNetKey(raw string) string {
+	name := strings.ToLower(raw)
+	if alias, ok := adoNetAliases[name]; ok {
+		return alias
+	}
+
+	return name
+}
+
+// splitSqlServerHost unpacks "tcp:host\instance,port" into its parts.
+func splitSqlServerHost(c *connection) {
+	host := c.Host
+	if protocol, rest, ok := strings.Cut(host, ":"); ok && !strings.Contains(rest, ":") && isSqlServerProtocol(protocol) {
+		host = rest
+	}
+
+	if h, port, ok := strings.Cut(host, ","); ok {
+		host = strings.TrimSpace(h)
+		if c.Port == "" {
+			c.assign(keyPort, strings.TrimSpace(port))
+		}
+	} else if h, port, err := net.SplitHostPort(host); err == nil && c.Port == "" {
+		host = h
+		c.assign(keyPort, port)
+	}
+
+	if h, instance, ok := strings.Cut(host, `\`); ok {
+		host = h
+		c.addProperty("instance", instance)
+	}
+
+	c.Host = trimBrackets(host)
+}
+
+func isSqlServerProtocol(protocol string) bool {
+	switch strings.ToLower(protocol) {
+	case "tcp", "np", "lpc", "admin":
+		return true
+	}
+
+	return false
+}
+
+func renderADONET(c *connection) (string, error) {
+	// a comma after the server is its port
+	if err := oneHost(c, "ado.net", "ADO.NET"); err != nil {
+		return "", err
+	}
+
+	var pairs []string
+
+	write := func(key string, value string) {
+		pairs = append(pairs, key+"="+quoteADONET(value))
+	}
+
+	server, socket := c.Host, c.SocketPath() != ""
+	if instance := c.GetProperty("instance"); instance != "" {
+		server += `\` + instance
+	}
+
+	// a socket path may hold a comma, so its port is written on its own
+	if c.Port != "" && !socket {
+		server += "," + c.Port
+	}
+
+	if server != "" {
+		write("Server", server)
+	}
+
+	if c.Port != "" && socket {
+		write("Port", c.Port)
+	}
+
+	if socket && c.isForAny(optionFileTypes...) && !c.HasProperty("protocol") {
+		write("Protocol", "Unix")
+	}
+
+	if c.Database != "" {
+		write("Database", c.Database)
+	}
+
+	if c.Username != nil {
+		write("User ID", *c.Username)
+	}
+
+	if c.Password != nil {
+		write("Password", *c.Password)
+	}
+
+	for _, property := range c.PropertyList() {
+		if property.Key != "instance" {
+			write(property.name(), property.Value)
+		}
+	}
+
+	return strings.Join(pairs, ";"), nil
+}
+
+func quoteADONET(value string) string {
+	if !strings.ContainsAny(value, `;"'`) && strings.TrimSpace(value) == value {
+		return value
+	}
+
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var adoNetChecks = map[string]dataProvider{
+	"adonet - sql server with port and instance": {
+		input: `Server=tcp:db.example.com\SQLEXPRESS,1433;Initial Catalog=app;User ID=sa;Password="p;w""d";Encrypt=True;`,
+		expected: &connection{
+			Username:    toPtr("sa"),
+			Password:    toPtr(`p;w"d`),
+			Host:        "db.example.com",
+			Port:        "1433",
+			NumericPort: 1433,
+			Database:    "app",
+			Properties: map[string][]string{
+				"instance": {"SQLEXPRESS"},
+				"encrypt":  {"True"},
+			},
+		},
+	},
+	"adonet - data source and integrated security": {
+		input: "Data Source = localhost ; Integrated Security = SSPI ; Database = app",
+		expected: &connection{
+			Host:     "localhost",
+			Database: "app",
+			Properties: map[string][]string{
+				"integrated security": {"SSPI"},
+			},
+		},
+	},
+	"adonet - npgsql style with host and port": {
+		input: "Host=db.example.com;Port=5432;Username=alice;Password='it''s';Database=app;Application Name=api",
+		expected: &connection{
+			Username:    toPtr("alice"),
+			Password:    toPtr("it's"),
+			Host:        "db.example.com",
+			Port:        "5432",
+			NumericPort: 5432,
+			Database:    "app",
+			Properties: map[string][]string{
+				"application name": {"api"},
+			},
+		},
+	},
+	"adonet - explicit port wins over the server suffix": {
+		input: "Server=db.example.com:1444;Port=1433;Trusted_Connection=yes",
+		expected: &connection{
+			Host:        "db.example.com:1444",
+			Port:        "1433",
+			NumericPort: 1433,
+			Properties: map[string][]string{
+				"trusted_connection": {"yes"},
+			},
+		},
+	},
+	"adonet - uid and pwd": {
+		input: "Server=host:1433;UID=sa;PWD=secret;TrustServerCertificate=true",
+		expected: &connection{
+			Username:    toPtr("sa"),
+			Password:    toPtr("secret"),
+			Host:        "host",
+			Port:        "1433",
+			NumericPort: 1433,
+			Properties: map[string][]string{
+				"trustservercertificate": {"true"},
+			},
+		},
+	},
+	"adonet - unterminated quote": {
+		input:        `Server=host;Password="secret`,
+		expectsError: true,
+	},
+}
+
+func TestADONET(t *testing.T) {
+	for name, check := range adoNetChecks {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, FormatADONET, Detect(check.input))
+
+			conn, err := Parse(check.input)
+
+			if check.expectsError {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assertConnection(t, check.expected, conn)
+		})
+	}
+}
+
+func TestADONETStrict(t *testing.T) {
+	_, err := NewParser(WithStrict(true)).ParseAs(FormatADONET, "Server=a;Data Source=b")
+
+	assert.EqualError(t, err, "syntax error at offset 9: host is set more than once")
+}
@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const amqpDefaultPort = "5672"
+const amqpsDefaultPort = "5671"
+const amqpDefaultVHost = "/"
+
+// amqpTLSOptions only make sense on an amqps connection.
+var amqpTLSOptions = []string{"cacertfile", "certfile", "keyfile", "verify", "fail_if_no_peer_cert", "server_name_indication"}
+
+// AMQPOptions holds the settings of an AMQP 0-9-1 connection string, read as
+// in the RabbitMQ URI specification and its query parameters.
+type AMQPOptions struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// VHost is the decoded path: "/" for amqp://host/%2F and for a string
+	// without a path, "" for amqp://host/
+	VHost string
+	TLS   bool
+	// Heartbeat is the heartbeat parameter, in seconds; 0 when it is not set
+	// or turns heartbeats off
+	Heartbeat time.Duration
+	// ConnectionTimeout is the connection_timeout parameter, in milliseconds
+	ConnectionTimeout time.Duration
+	ChannelMax        int
+	FrameMax          int
+	AuthMechanisms    []string
+	// CACertFile and the fields below it come from the TLS parameters, which
+	// an amqp:// string must not have
+	CACertFile       string
+	CertFile         string
+	KeyFile          string
+	Verify           string
+	FailIfNoPeerCert bool
+	ServerName       string
+}
+
+func isAMQPScheme(scheme string) bool {
+	return strings.EqualFold(scheme, "amqp") || strings.EqualFold(scheme, "amqps")
+}
+
+func (c *connection) isAMQP() bool {
+	return c.Type != nil && isAMQPScheme(*c.Type)
+}
+
+// AMQP reads an amqp:// or amqps:// connection. amqps means TLS, on port 5671
+// unless the string names another one.
+func (c *connection) AMQP() (*AMQPOptions, error) {
+	if !c.isAMQP() {
+		return nil, fmt.Errorf("%s is not an AMQP connection", describeType(c))
+	}
+
+	o := &AMQPOptions{
+		Username:       stringOf(c.Username),
+		Password:       stringOf(c.Password),
+		VHost:          c.Database,
+		TLS:            strings.EqualFold(*c.Type, "amqps"),
+		AuthMechanisms: c.GetProperties("auth_mechanism"),
+		CACertFile:     c.GetProperty("cacertfile"),
+		CertFile:       c.GetProperty("certfile"),
+		KeyFile:        c.GetProperty("keyfile"),
+		Verify:         c.GetProperty("verify"),
+		ServerName:     c.GetProperty("server_name_indication"),
+	}
+
+	// a "/" in the vhost has to be escaped, the path has only one segment
+	if strings.Contains(strings.TrimPrefix(c.Path, "/"), "/") {
+		return nil, fmt.Errorf("vhost path %q has more than one segment", c.Path)
+	}
+
+	if o.VHost == "" && c.Path != "/" {
+		o.VHost = amqpDefaultVHost
+	}
+
+	if err := o.readAddress(c); err != nil {
+		return nil, err
+	}
+
+	if err := o.readTuning(c); err != nil {
+		return nil, err
+	}
+
+	if err := o.readTLS(c); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *AMQPOptions) readAddress(c *connection) error {
+	endpoints := c.Endpoints()
+	if len(endpoints) > 1 {
+		return fmt.Errorf("%s takes one host, not %d", *c.Type, len(endpoints))
+	}
+
+	if len(endpoints) == 0 {
+		endpoints = []Endpoint{{Host: "localhost"}}
+	}
+
+	port := amqpDefaultPort
+	if o.TLS {
+		port = amqpsDefaultPort
+	}
+
+	endpoints, err := withDefaultPort(endpoints, port)
+	if err != nil {
+		return err
+	}
+
+	o.Host = firstOf(endpoints[0].Host, "localhost")
+	o.Port, _ = strconv.Atoi(endpoints[0].Port)
+
+	return nil
+}
+
+func (o *AMQPOptions) readTuning(c *connection) error {
+	heartbeat, err := amqpNumber(c, "heartbeat", 65535)
+	if err != nil {
+		return err
+	}
+
+	timeout, err := amqpNumber(c, "connection_timeout", math.MaxInt32)
+	if err != nil {
+		return err
+	}
+
+	if o.ChannelMax, err = amqpNumber(c, "channel_max", 65535); err != nil {
+		return err
+	}
+
+	if o.FrameMax, err = amqpNumber(c, "frame_max", math.MaxInt32); err != nil {
+		return err
+	}
+
+	o.Heartbeat = time.Duration(heartbeat) * time.Second
+	o.ConnectionTimeout = time.Duration(timeout) * time.Millisecond
+
+	return nil
+}
+
+func (o *AMQPOptions) readTLS(c *connection) error {
+	if !o.TLS {
+		for _, key := range amqpTLSOptions {
+			if c.HasProperty(key) {
+				return fmt.Errorf("%s needs amqps", key)
+			}
+		}
+
+		return nil
+	}
+
+	if err := oneOf("verify", o.Verify, "verify_peer", "verify_none"); err != nil {
+		return err
+	}
+
+	if v := c.GetProperty("fail_if_no_peer_cert"); v != "" {
+		fail, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("fail_if_no_peer_cert %q is not true or false", v)
+		}
+
+		o.FailIfNoPeerCert = fail
+	}
+
+	return nil
+}
+
+// amqpNumber reads a whole number parameter between 0 and highest, 0 when it
+// is not set.
+func amqpNumber(c *connection, key string, highest int) (int, error) {
+	v := c.GetProperty(key)
+	if v == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || n > highest {
+		return 0, fmt.Errorf("%s %q is not a number between 0 and %d", key, v, highest)
+	}
+
+	return n, nil
+}
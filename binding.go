@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const bindingKeyUri = "uri"
+
+func (p *parser) FromBindingDir(path string) (*connection, error) {
+	return p.resolve(p.fromBindingDir(path))
+}
+
+func (p *parser) fromBindingDir(path string) (*connection, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+
+	for _, entry := range entries {
+		// kubernetes projects the files through hidden "..data" symlinks
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		files[entry.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+
+	return p.fromFields(files, func(name string) string {
+		return "file:" + filepath.Join(path, name)
+	})
+}
+
+func (p *parser) fromFields(fields map[string]string, origin func(name string) string) (*connection, error) {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := &connection{}
+
+	for _, name := range names {
+		if name == bindingKeyUri {
+			continue
+		}
+
+		field, err := p.assign(c, name, fields[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		if origin != nil {
+			c.setOrigin(field, origin(name))
+		}
+	}
+
+	uri, ok := fields[bindingKeyUri]
+	if !ok {
+		return c, nil
+	}
+
+	fromUri, err := p.parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if origin != nil {
+		fromUri.Label(origin(bindingKeyUri))
+	}
+
+	return c.Overlay(fromUri), nil
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+var envAliases = map[string]string{
+	"name": keyDatabase,
+}
+
+// environment returns the map the caller passed, or else a copy of the
+// process environment.
+func environment(environ []map[string]string) map[string]string {
+	if len(environ) > 0 {
+		return environ[0]
+	}
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+
+	return env
+}
+
+func (p *parser) FromEnv(prefix string, environ ...map[string]string) (*connection, error) {
+	return p.resolve(p.fromEnv(prefix, environ...))
+}
+
+func (p *parser) fromEnv(prefix string, environ ...map[string]string) (*connection, error) {
+	env := environment(environ)
+
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	var names []string
+	for name := range env {
+		if len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	c := &connection{}
+
+	for _, name := range names {
+		key := strings.ToLower(name[len(prefix):])
+		if alias, ok := envAliases[key]; ok {
+			key = alias
+		}
+
+		field, err := p.assign(c, key, env[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		c.setOrigin(field, "env:"+name)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromEnv(t *testing.T) {
+	env := map[string]string{
+		"DB_HOST":     "db.example.com",
+		"DB_PORT":     "5432",
+		"DB_USER":     "alice",
+		"DB_PASSWORD": "",
+		"DB_NAME":     "users",
+		"DB_SSLMODE":  "require",
+		"DB_TYPE":     "postgres",
+		"DB_":         "ignored",
+		"DBX_HOST":    "ignored",
+		"CACHE_HOST":  "ignored",
+	}
+
+	expected := &connection{
+		Type:        toPtr("postgres"),
+		Username:    toPtr("alice"),
+		Password:    toPtr(""),
+		Host:        "db.example.com",
+		Port:        "5432",
+		NumericPort: 5432,
+		Database:    "users",
+		Properties: map[string][]string{
+			"sslmode": {"require"},
+		},
+		origins: map[string]string{
+			"type":     "env:DB_TYPE",
+			"username": "env:DB_USER",
+			"password": "env:DB_PASSWORD",
+			"host":     "env:DB_HOST",
+			"port":     "env:DB_PORT",
+			"database": "env:DB_NAME",
+			"sslmode":  "env:DB_SSLMODE",
+		},
+	}
+
+	conn, err := NewParser().FromEnv("DB", env)
+	assert.NoError(t, err)
+	assertConnection(t, expected, conn)
+
+	conn, err = NewParser().FromEnv("DB_", env)
+	assert.NoError(t, err)
+	assertConnection(t, expected, conn)
+}
+
+func TestFromEnvWithoutMatchingVariables(t *testing.T) {
+	conn, err := NewParser().FromEnv("DB", map[string]string{"HOME": "/root"})
+
+	assert.NoError(t, err)
+	assertConnection(t, &connection{}, conn)
+}
+
+func TestFromEnvReadsProcessEnvironment(t *testing.T) {
+	t.Setenv("PARSER_TEST_DB_HOST", "env.example.com")
+	t.Setenv("PARSER_TEST_DB_DBNAME", "app")
+
+	conn, err := NewParser().FromEnv("PARSER_TEST_DB")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "env.example.com", conn.Host)
+	assert.Equal(t, "app", conn.Database)
+	assert.Equal(t, "env:PARSER_TEST_DB_HOST", conn.Origin("host"))
+	assert.Equal(t, "env:PARSER_TEST_DB_DBNAME", conn.Origin("database"))
+}
+
+func TestFromEnvRunsResolvers(t *testing.T) {
+	called := false
+	resolver := func(c *connection) error {
+		called = true
+		assert.Equal(t, "example.com", c.Host)
+
+		return nil
+	}
+
+	_, err := NewParser().Resolver(resolver).FromEnv("DB", map[string]string{"DB_HOST": "example.com"})
+
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
@@ -0,0 +1,237 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const memoryPath = ":memory:"
+
+// FileOptions holds the settings of a file database, read from its path and
+// from the SQLite or DuckDB URI parameters.
+type FileOptions struct {
+	Path       string
+	Memory     bool
+	ReadOnly   bool
+	Mode       string // SQLite: ro, rw, rwc or memory
+	Cache      string // SQLite: shared or private
+	Immutable  bool   // SQLite
+	VFS        string // SQLite
+	AccessMode string // DuckDB: automatic, read_only or read_write
+	Threads    int    // DuckDB
+}
+
+func isFileScheme(scheme string) bool {
+	switch strings.ToLower(scheme) {
+	case "sqlite", "sqlite3", "file", "duckdb":
+		return true
+	}
+
+	return false
+}
+
+func (c *connection) isFileDatabase() bool {
+	return c.Type != nil && isFileScheme(*c.Type)
+}
+
+// fromFileURL reads the URL of a file database, whose path names a file
+// rather than a database:
+//
+//	sqlite:app.db, sqlite://data/app.db  relative to the working directory
+//	sqlite:///var/lib/app.db             absolute
+//	sqlite::memory:, file::memory:       in memory
+func (p *parser) fromFileURL(scheme string, rest string) (*connection, error) {
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, query, _ := strings.Cut(rest, "?")
+
+	// the scheme is lower case, as net/url makes it for the other URLs
+	c := &connection{}
+	c.assign(keyType, strings.ToLower(scheme))
+
+	host, path := splitFilePath(scheme, rest)
+
+	unescaped, err := url.PathUnescape(path)
+	if err != nil {
+		return nil, fmt.Errorf("parse %q: %w", scheme+":"+rest, err)
+	}
+
+	c.Host = host
+	c.Path, c.Database = unescaped, unescaped
+
+	// same as u.Query(), but keeps the order of the keys
+	_ = c.addQuery(query)
+
+	return c, nil
+}
+
+// splitFilePath finds the host and the (still escaped) path of a file URL
+// without its scheme. Only file: URLs name a host; in sqlite://data/app.db
+// the authority is the first directory of a relative path.
+func splitFilePath(scheme string, rest string) (string, string) {
+	host, path := "", rest
+
+	if strings.HasPrefix(rest, "//") {
+		authority, tail, slashed := strings.Cut(rest[2:], "/")
+
+		switch {
+		case authority == "" && !slashed:
+			path = ""
+		case authority == "":
+			path = "/" + tail
+		case strings.EqualFold(scheme, "file"):
+			if !strings.EqualFold(authority, "localhost") {
+				host = authority
+			}
+
+			path = "/" + tail
+		case slashed:
+			path = authority + "/" + tail
+		default:
+			path = authority
+		}
+	}
+
+	trimmed := strings.TrimLeft(path, "/")
+	switch {
+	case strings.HasPrefix(trimmed, memoryPath):
+		// sqlite:///:memory: is still in memory
+		path = trimmed
+	case len(trimmed) < len(path):
+		// sqlite:////var/lib/app.db has one slash too many, /C:/app.db one
+		// before a Windows drive
+		path = "/" + trimmed
+		if isWindowsDrive(trimmed) {
+			path = trimmed
+		}
+	}
+
+	return host, path
+}
+
+func isWindowsDrive(path string) bool {
+	return len(path) >= 3 && path[1] == ':' && (path[2] == '/' || path[2] == '\\') &&
+		(path[0] >= 'a' && path[0] <= 'z' || path[0] >= 'A' && path[0] <= 'Z')
+}
+
+// fileURLPath writes a path the way fromFileURL reads it back: an absolute
+// path after "//", anything else as it is.
+func fileURLPath(host string, path string) string {
+	escaped := (&url.URL{Path: path}).EscapedPath()
+
+	switch {
+	case host != "":
+		return "//" + host + "/" + strings.TrimLeft(escaped, "/")
+	case strings.HasPrefix(path, "/"):
+		return "//" + escaped
+	case isWindowsDrive(path):
+		return "///" + escaped
+	}
+
+	return escaped
+}
+
+func renderFileURL(c *connection) string {
+	path := c.Path
+	if path == "" {
+		path = c.Database
+	}
+
+	rendered := *c.Type + ":" + fileURLPath(c.Host, path)
+	if query := encodeQuery(c.PropertyList()); query != "" {
+		rendered += "?" + query
+	}
+
+	return rendered
+}
+
+// FileOptions reads the path and the URI parameters of a SQLite or DuckDB
+// connection, and checks the values of the ones it knows.
+func (c *connection) FileOptions() (*FileOptions, error) {
+	if !c.isFileDatabase() {
+		return nil, fmt.Errorf("%s is not a file database", describeType(c))
+	}
+
+	o := &FileOptions{
+		Path:       c.Path,
+		Mode:       c.GetProperty("mode"),
+		Cache:      c.GetProperty("cache"),
+		VFS:        c.GetProperty("vfs"),
+		AccessMode: strings.ToLower(c.GetProperty("access_mode")),
+	}
+
+	if o.Path == "" {
+		o.Path = c.Database
+	}
+
+	if err := oneOf("mode", o.Mode, "ro", "rw", "rwc", "memory"); err != nil {
+		return nil, err
+	}
+
+	if err := oneOf("cache", o.Cache, "shared", "private"); err != nil {
+		return nil, err
+	}
+
+	if err := oneOf("access_mode", o.AccessMode, "automatic", "read_only", "read_write"); err != nil {
+		return nil, err
+	}
+
+	if v := c.GetProperty("immutable"); v != "" {
+		immutable, ok := parseURIBool(v)
+		if !ok {
+			return nil, fmt.Errorf("immutable %q is not a boolean", v)
+		}
+
+		o.Immutable = immutable
+	}
+
+	if v := c.GetProperty("threads"); v != "" {
+		threads, err := strconv.Atoi(v)
+		if err != nil || threads < 1 {
+			return nil, fmt.Errorf("threads %q is not a positive number", v)
+		}
+
+		o.Threads = threads
+	}
+
+	// DuckDB opens an in-memory database when it is given no path
+	o.Memory = strings.HasPrefix(o.Path, memoryPath) || o.Mode == "memory" || (c.IsFor("duckdb") && o.Path == "")
+	o.ReadOnly = o.Mode == "ro" || o.Immutable || o.AccessMode == "read_only"
+
+	return o, nil
+}
+
+func describeType(c *connection) string {
+	if c.Type == nil {
+		return "a connection without a type"
+	}
+
+	return strconv.Quote(*c.Type)
+}
+
+func oneOf(key string, value string, allowed ...string) error {
+	if value == "" {
+		return nil
+	}
+
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s %q is not one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+// parseURIBool reads a boolean the way SQLite reads URI parameters.
+func parseURIBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "1", "yes", "true", "on":
+		return true, true
+	case "0", "no", "false", "off":
+		return false, true
+	}
+
+	return false, false
+}
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileURL(t *testing.T) {
+	checks := map[string]struct {
+		input string
+		host  string
+		path  string
+	}{
+		"absolute":                       {"sqlite:///var/lib/app.db", "", "/var/lib/app.db"},
+		"absolute with a slash too many": {"sqlite:////var/lib/app.db", "", "/var/lib/app.db"},
+		"absolute without authority":     {"sqlite3:/var/lib/app.db", "", "/var/lib/app.db"},
+		"relative":                       {"sqlite:app.db", "", "app.db"},
+		"relative with authority":        {"sqlite://data/app.db", "", "data/app.db"},
+		"relative file":                  {"file:data/app.db", "", "data/app.db"},
+		"escaped":                        {"sqlite:///srv/my%20app.db", "", "/srv/my app.db"},
+		"windows drive":                  {"sqlite:///C:/data/app.db", "", "C:/data/app.db"},
+		"file on localhost":              {"file://localhost/var/app.db", "", "/var/app.db"},
+		"file on a host":                 {"file://nas/share/app.db", "nas", "/share/app.db"},
+		"memory":                         {"sqlite::memory:", "", ":memory:"},
+		"memory after slashes":           {"sqlite:///:memory:", "", ":memory:"},
+		"named duckdb memory":            {"duckdb::memory:cache", "", ":memory:cache"},
+		"no path":                        {"duckdb://", "", ""},
+		"fragment":                       {"sqlite:app.db#top", "", "app.db"},
+		"upper case scheme":              {"SQLite:app.db", "", "app.db"},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			conn, err := Parse(check.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.host, conn.Host)
+			assert.Equal(t, check.path, conn.Path)
+			assert.Equal(t, check.path, conn.Database)
+
+			// rendering gives back an equivalent URL
+			rendered, err := conn.Render(FormatURL)
+			assert.NoError(t, err)
+
+			again, err := Parse(rendered)
+
+			assert.NoError(t, err)
+			assertConnection(t, conn, again)
+		})
+	}
+
+	conn, err := Parse("SQLite:app.db")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sqlite", *conn.Type)
+	assert.True(t, conn.IsFor("sqlite"))
+}
+
+func TestFileURLQuery(t *testing.T) {
+	conn, err := Parse("file:app.db?mode=ro&cache=shared&_busy_timeout=5000")
+
+	assert.NoError(t, err)
+	assertConnection(t, &connection{
+		Type:     toPtr("file"),
+		Database: "app.db",
+		Path:     "app.db",
+		Properties: map[string][]string{
+			"mode":          {"ro"},
+			"cache":         {"shared"},
+			"_busy_timeout": {"5000"},
+		},
+	}, conn)
+
+	rendered, err := conn.Render(FormatURL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "file:app.db?mode=ro&cache=shared&_busy_timeout=5000", rendered)
+
+	_, err = Parse("sqlite:///%zz.db")
+	assert.EqualError(t, err, `parse "sqlite:///%zz.db": invalid URL escape "%zz"`)
+}
+
+func TestFilePathFromOtherForms(t *testing.T) {
+	conn, err := Parse("type=sqlite database=/var/lib/app.db")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/lib/app.db", conn.Path)
+
+	conn, err = Parse("jdbc:sqlite:/var/lib/app.db")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/lib/app.db", conn.Path)
+
+	conn, err = Parse("postgres://db.example.com/app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", conn.Path)
+}
+
+func TestFileOptions(t *testing.T) {
+	checks := map[string]struct {
+		input    string
+		expected *FileOptions
+	}{
+		"sqlite read only": {
+			input:    "file:/srv/app.db?mode=ro&cache=private&vfs=unix-none",
+			expected: &FileOptions{Path: "/srv/app.db", ReadOnly: true, Mode: "ro", Cache: "private", VFS: "unix-none"},
+		},
+		"sqlite immutable": {
+			input:    "sqlite:app.db?immutable=yes",
+			expected: &FileOptions{Path: "app.db", ReadOnly: true, Immutable: true},
+		},
+		"sqlite shared memory": {
+			input:    "file:memdb1?mode=memory&cache=shared",
+			expected: &FileOptions{Path: "memdb1", Memory: true, Mode: "memory", Cache: "shared"},
+		},
+		"sqlite memory": {
+			input:    "sqlite::memory:",
+			expected: &FileOptions{Path: ":memory:", Memory: true},
+		},
+		"duckdb": {
+			input:    "duckdb:///data/warehouse.duckdb?access_mode=READ_ONLY&threads=4",
+			expected: &FileOptions{Path: "/data/warehouse.duckdb", ReadOnly: true, AccessMode: "read_only", Threads: 4},
+		},
+		"duckdb without a path": {
+			input:    "duckdb:",
+			expected: &FileOptions{Memory: true},
+		},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			conn, err := Parse(check.input)
+			assert.NoError(t, err)
+
+			options, err := conn.FileOptions()
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.expected, options)
+		})
+	}
+}
+
+func TestFileOptionsErrors(t *testing.T) {
+	checks := map[string]string{
+		"sqlite:app.db?mode=rx":         `mode "rx" is not one of ro, rw, rwc, memory`,
+		"sqlite:app.db?cache=none":      `cache "none" is not one of shared, private`,
+		"sqlite:app.db?immutable=maybe": `immutable "maybe" is not a boolean`,
+		"duckdb:a.db?access_mode=write": `access_mode "write" is not one of automatic, read_only, read_write`,
+		"duckdb:a.db?threads=0":         `threads "0" is not a positive number`,
+		"postgres://db.example.com/app": `"postgres" is not a file database`,
+		"host=db.example.com":           "a connection without a type is not a file database",
+	}
+
+	for input, expected := range checks {
+		t.Run(input, func(t *testing.T) {
+			conn, err := Parse(input)
+			assert.NoError(t, err)
+
+			_, err = conn.FileOptions()
+
+			assert.EqualError(t, err, expected)
+		})
+	}
+}
+
+func TestDocumentSetFilePath(t *testing.T) {
+	checks := map[string]struct {
+		input    string
+		value    string
+		expected string
+	}{
+		"relative to absolute": {"sqlite:app.db?mode=ro", "/var/lib/app.db", "sqlite:///var/lib/app.db?mode=ro"},
+		"absolute to relative": {"sqlite:///var/lib/app.db", "data/app.db", "sqlite:data/app.db"},
+		"memory":               {"duckdb:///a.duckdb", ":memory:", "duckdb::memory:"},
+		"escaped":              {"file:a.db", "my app.db", "file:my%20app.db"},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			doc, err := ParseLossless(check.input)
+			assert.NoError(t, err)
+
+			assert.NoError(t, doc.Set("database", check.value))
+			assert.Equal(t, check.expected, doc.String())
+
+			conn, err := doc.Connection()
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.value, conn.Path)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Format string
+
+const FormatURL Format = "url"
+const FormatPairs Format = "pairs"
+const FormatADONET Format = "adonet"
+const FormatMySQLDSN Format = "mysql-dsn"
+const FormatJDBC Format = "jdbc"
+const FormatODBC Format = "odbc"
+const FormatEZConnect Format = "ezconnect"
+const FormatTNS Format = "tns"
+const FormatHostList Format = "host-list"
+const FormatPDO Format = "pdo"
+
+type Detection struct {
+	Format     Format
+	Confidence float64
+}
+
+// Detector scores how likely the input is in a format, from 0 (not at all) to 1.
+type Detector func(input string) float64
+
+type ParseFunc func(input string) (*Connection, error)
+
+type RenderFunc func(c *Connection) (string, error)
+
+type format struct {
+	name     Format
+	priority int
+	detect   func(p *parser, input string) float64
+	parse    func(p *parser, input string) (*connection, error)
+	render   func(c *connection) (string, error)
+}
+
+var formatsMu sync.RWMutex
+
+var builtinFormats = []format{
+	{name: FormatURL, detect: detectURL, parse: (*parser).fromUrl, render: renderURL},
+	{name: FormatPairs, detect: detectPairs, parse: (*parser).fromPair, render: renderPairs},
+	{name: FormatADONET, detect: detectADONET, parse: (*parser).fromADONET, render: renderADONET},
+	{name: FormatMySQLDSN, detect: detectMySQLDSN, parse: (*parser).fromMySQLDSN, render: renderMySQLDSN},
+	{name: FormatJDBC, detect: detectJDBC, parse: (*parser).fromJDBC, render: renderJDBC},
+	{name: FormatODBC, detect: detectODBC, parse: (*parser).fromODBC, render: renderODBC},
+	{name: FormatEZConnect, detect: detectEZConnect, parse: (*parser).fromEZConnect, render: renderEZConnect},
+	{name: FormatTNS, detect: detectTNS, parse: (*parser).fromTNS, render: renderTNS},
+	{name: FormatHostList, detect: detectHostList, parse: (*parser).fromHostList, render: renderHostList},
+	{name: FormatPDO, detect: detectPDO, parse: (*parser).fromPDO, render: renderPDO},
+}
+
+var formats = builtinFormats
+
+// RegisterFormat adds a format to auto-detection, ParseAs and Render. The
+// detector and the render function may be nil. When two formats score the same
+// confidence, the one with the higher priority wins; the default priority is 0,
+// the same as the built-in formats.
+func RegisterFormat(name Format, detector Detector, parse ParseFunc, render RenderFunc, priority ...int) error {
+	if name == "" {
+		return errors.New("format name is empty")
+	}
+
+	if parse == nil {
+		return fmt.Errorf("format %q has no parse function", name)
+	}
+
+	f := format{
+		name:   name,
+		detect: func(p *parser, input string) float64 { return 0 },
+		parse: func(p *parser, input string) (*connection, error) {
+			c, err := parse(input)
+			if err == nil && c == nil {
+				return nil, fmt.Errorf("format %q returned no connection", name)
+			}
+
+			return c, err
+		},
+		render: render,
+	}
+
+	if detector != nil {
+		f.detect = func(p *parser, input string) float64 { return detector(input) }
+	}
+
+	if len(priority) > 0 {
+		f.priority = priority[0]
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if _, ok := findFormat(formats, name); ok {
+		return fmt.Errorf("format %q is already registered", name)
+	}
+
+	// parsers read the slice without holding the lock, so it is never changed in place
+	registered := append(formats[:len(formats):len(formats)], f)
+	sort.SliceStable(registered, func(i, j int) bool {
+		return registered[i].priority > registered[j].priority
+	})
+
+	formats = registered
+
+	return nil
+}
+
+func registeredFormats() []format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	return formats
+}
+
+var urlSchemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.\-]*://`)
+var opaqueSchemePattern = regexp.MustCompile(`^(?i:sqlite3?|file|duckdb):`)
+
+func detectURL(p *parser, input string) float64 {
+	switch {
+	case urlSchemePattern.MatchString(input):
+		return 0.9
+	case strings.HasPrefix(input, "//"):
+		return 0.8
+	case opaqueSchemePattern.MatchString(input):
+		return 0.7
+	}
+
+	return 0
+}
+
+func detectPairs(p *parser, input string) float64 {
+	// the delimited form is the fallback, so it never scores zero
+	if strings.Contains(input, p.separator) {
+		return 0.5
+	}
+
+	return 0.1
+}
+
+func findFormat(formats []format, name Format) (format, bool) {
+	for _, f := range formats {
+		if f.name == name {
+			return f, true
+		}
+	}
+
+	return format{}, false
+}
+
+func (p *parser) DetectAll(input string) []Detection {
+	var detections []Detection
+	for _, f := range registeredFormats() {
+		if confidence := f.detect(p, input); confidence > 0 {
+			detections = append(detections, Detection{Format: f.name, Confidence: confidence})
+		}
+	}
+
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].Confidence > detections[j].Confidence
+	})
+
+	return detections
+}
+
+func (p *parser) Detect(input string) Format {
+	formats := registeredFormats()
+
+	// a URL scheme is the common case, and no built-in format scores higher
+	// on it, so the other detectors only run once a format is registered
+	if len(formats) == len(builtinFormats) && detectURL(p, input) >= 0.9 {
+		return FormatURL
+	}
+
+	// same answer as the head of DetectAll, without building the list
+	best, confidence := FormatPairs, 0.0
+	for _, f := range formats {
+		if score := f.detect(p, input); score > confidence {
+			best, confidence = f.name, score
+		}
+	}
+
+	return best
+}
+
+func (p *parser) ParseAs(name Format, input string) (*connection, error) {
+	return p.resolve(p.parseAs(name, input))
+}
+
+func (p *parser) parseAs(name Format, input string) (*connection, error) {
+	f, ok := findFormat(registeredFormats(), name)
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", name)
+	}
+
+	return f.parse(p, input)
+}
+
+func (c *connection) Render(name Format) (string, error) {
+	f, ok := findFormat(registeredFormats(), name)
+	if !ok {
+		return "", fmt.Errorf("unknown format %q", name)
+	}
+
+	if f.render == nil {
+		return "", fmt.Errorf("format %q cannot be rendered", name)
+	}
+
+	return f.render(c)
+}
+
+func DetectAll(input string) []Detection {
+	return defaultParser.DetectAll(input)
+}
+
+func Detect(input string) Format {
+	return defaultParser.Detect(input)
+}
+
+func ParseAs(name Format, input string) (*connection, error) {
+	return defaultParser.ParseAs(name, input)
+}
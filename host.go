@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const maxLabelLength = 63
+
+// hostProfile is the IDNA lookup profile without the STD3 rules, which would
+// refuse the underscores of names such as my_service.internal.
+var hostProfile = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.StrictDomainName(false))
+
+// IsIP reports whether Host is an IP address, with or without an IPv6 zone.
+func (c *connection) IsIP() bool {
+	_, ok := c.hostAddr()
+	return ok
+}
+
+// IsLoopback reports whether Host is a loopback address, localhost or a name
+// under .localhost.
+func (c *connection) IsLoopback() bool {
+	if addr, ok := c.hostAddr(); ok {
+		return addr.IsLoopback()
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(c.Host, "."))
+	return host == "localhost" || strings.HasSuffix(host, ".localhost")
+}
+
+// IsPrivate reports whether Host is a private address, as in RFC 1918 for
+// IPv4 and RFC 4193 for IPv6. A host name is never private.
+func (c *connection) IsPrivate() bool {
+	addr, ok := c.hostAddr()
+	return ok && addr.IsPrivate()
+}
+
+func (c *connection) hostAddr() (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(trimBrackets(c.Host))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	// ::ffff:127.0.0.1 is classified as 127.0.0.1
+	return addr.Unmap(), true
+}
+
+// NormalizeHost returns the canonical form of a host: an IP address as
+// netip prints it, keeping its zone, and a host name as the IDNA lookup
+// profile maps it, underscores allowed, in lower case, without its trailing dot and with every
+// internationalised label converted to punycode ("Bücher.example." gives
+// "xn--bcher-kva.example"). A socket path is returned as it is.
+func NormalizeHost(host string) (string, error) {
+	if host == "" || isSocketPath(host) {
+		return host, nil
+	}
+
+	if addr, err := netip.ParseAddr(trimBrackets(host)); err == nil {
+		return addr.String(), nil
+	}
+
+	ascii, err := hostProfile.ToASCII(strings.TrimSuffix(host, "."))
+	if err != nil {
+		return "", fmt.Errorf("host %q: %w", host, err)
+	}
+
+	for _, label := range strings.Split(ascii, ".") {
+		if label == "" {
+			return "", fmt.Errorf("host %q: empty label", host)
+		}
+
+		if len(label) > maxLabelLength {
+			return "", fmt.Errorf("host %q: label %q is longer than %d bytes once encoded", host, label, maxLabelLength)
+		}
+
+		// letters, digits, hyphens and underscores are left once encoded
+		for _, r := range label {
+			if !isHostRune(r) {
+				return "", fmt.Errorf("host %q: disallowed rune %U", host, r)
+			}
+		}
+	}
+
+	return ascii, nil
+}
+
+func isHostRune(r rune) bool {
+	return 'a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-' || r == '_'
+}
+
+// normalizeHosts applies NormalizeHost to Host and every endpoint in Hosts.
+// A socket path, even a relative one, is left alone.
+func (c *connection) normalizeHosts() error {
+	if c.SocketPath() != "" {
+		return nil
+	}
+
+	host, err := NormalizeHost(c.Host)
+	if err != nil {
+		return err
+	}
+
+	c.Host = host
+
+	for i := range c.Hosts {
+		if c.Hosts[i].Host, err = NormalizeHost(c.Hosts[i].Host); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// trimBrackets removes the brackets around an IPv6 address: [::1] gives ::1.
+func trimBrackets(host string) string {
+	if len(host) > 1 && host[0] == '[' && host[len(host)-1] == ']' {
+		return host[1 : len(host)-1]
+	}
+
+	return host
+}
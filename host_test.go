@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPv6Hosts(t *testing.T) {
+	checks := map[string]struct {
+		input   string
+		host    string
+		address string
+	}{
+		"url with a zone":       {"postgres://alice@[fe80::1%25eth0]:5432/app", "fe80::1%eth0", "[fe80::1%eth0]:5432"},
+		"url without a port":    {"postgres://[::1]/app", "::1", "::1"},
+		"bare host in pairs":    {"host=::1 port=5432", "::1", "[::1]:5432"},
+		"bracketed host":        {"host=[2001:db8::5] port=5432", "2001:db8::5", "[2001:db8::5]:5432"},
+		"mysql dsn with a zone": {"alice@tcp([fe80::1%eth0]:3306)/app", "fe80::1%eth0", "[fe80::1%eth0]:3306"},
+		"mysql dsn, no port":    {"alice@tcp([::1])/app", "::1", "::1"},
+		"ado.net":               {"Server=[::1],1433;Database=app", "::1", "[::1]:1433"},
+		"ipv4":                  {"host=10.0.0.5 port=5432", "10.0.0.5", "10.0.0.5:5432"},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			conn, err := Parse(check.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.host, conn.Host)
+			assert.Equal(t, check.address, conn.Address())
+
+			// every format writes the host back in a form it reads again
+			for _, format := range []Format{FormatURL, FormatPairs, FormatADONET, FormatMySQLDSN, FormatODBC, FormatEZConnect, FormatTNS} {
+				rendered, err := conn.Render(format)
+				assert.NoError(t, err, format)
+
+				parsed, err := ParseAs(format, rendered)
+
+				assert.NoError(t, err, format)
+				assert.Equal(t, conn.Host, parsed.Host, format)
+				assert.Equal(t, conn.Port, parsed.Port, format)
+			}
+		})
+	}
+}
+
+func TestHostClassification(t *testing.T) {
+	checks := map[string]struct {
+		host     string
+		ip       bool
+		loopback bool
+		private  bool
+	}{
+		"ipv4 loopback":        {"127.0.0.1", true, true, false},
+		"ipv6 loopback":        {"::1", true, true, false},
+		"mapped loopback":      {"::ffff:127.0.0.1", true, true, false},
+		"private ipv4":         {"192.168.1.20", true, false, true},
+		"unique local ipv6":    {"fd12:3456::1", true, false, true},
+		"link local with zone": {"fe80::1%eth0", true, false, false},
+		"public ipv4":          {"8.8.8.8", true, false, false},
+		"localhost":            {"LocalHost.", false, true, false},
+		"under .localhost":     {"db.localhost", false, true, false},
+		"host name":            {"db.example.com", false, false, false},
+		"no host":              {"", false, false, false},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			conn := &connection{Host: check.host}
+
+			assert.Equal(t, check.ip, conn.IsIP())
+			assert.Equal(t, check.loopback, conn.IsLoopback())
+			assert.Equal(t, check.private, conn.IsPrivate())
+		})
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	checks := map[string]string{
+		"DB.Example.COM.":      "db.example.com",
+		"Bücher.example":       "xn--bcher-kva.example",
+		"münchen.de":           "xn--mnchen-3ya.de",
+		"mu\u0308nchen.de":     "xn--mnchen-3ya.de",
+		"ドメイン名例.jp":            "xn--eckwd4c7cu47r2wf.jp",
+		"例え。テスト":               "xn--r8jz45g.xn--zckzah",
+		"[FE80::0001%eth0]":    "fe80::1%eth0",
+		"2001:DB8:0:0:0:0:0:1": "2001:db8::1",
+		"db_1":                 "db_1",
+		"My_Service.internal":  "my_service.internal",
+		"/var/run/postgresql":  "/var/run/postgresql",
+		"":                     "",
+	}
+
+	for input, expected := range checks {
+		t.Run(input, func(t *testing.T) {
+			host, err := NormalizeHost(input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, host)
+		})
+	}
+
+	_, err := NormalizeHost(strings.Repeat("ü", 60) + ".example")
+	assert.ErrorContains(t, err, "is longer than 63 bytes once encoded")
+
+	_, err = NormalizeHost("a b.com")
+	assert.EqualError(t, err, `host "a b.com": disallowed rune U+0020`)
+
+	_, err = NormalizeHost("a..b")
+	assert.EqualError(t, err, `host "a..b": empty label`)
+
+	_, err = NormalizeHost(".")
+	assert.EqualError(t, err, `host ".": empty label`)
+}
+
+func TestWithNormalizeHosts(t *testing.T) {
+	p := NewParser(WithNormalizeHosts(true))
+
+	conn, err := p.Parse("postgres://alice@Bücher.Example.:5432/app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "xn--bcher-kva.example", conn.Host)
+
+	conn, err = p.ParseAs(FormatEZConnect, "DB1.example.com,[0:0::1]:1521/svc")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Endpoint{{Host: "db1.example.com", Port: "1521"}, {Host: "::1", Port: "1521"}}, conn.Endpoints())
+
+	// a relative socket path is not a host name
+	conn, err = p.Parse("alice@unix(MySQL.sock)/app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "MySQL.sock", conn.Host)
+
+	conn, err = NewParser().Parse("postgres://DB.example.com/app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DB.example.com", conn.Host)
+}
+
+func TestDocumentSetIPv6Host(t *testing.T) {
+	doc, err := ParseLossless("postgres://alice@db.example.com:5432/app")
+	assert.NoError(t, err)
+
+	assert.NoError(t, doc.Set("host", "fe80::1%eth0"))
+	assert.Equal(t, "postgres://alice@[fe80::1%25eth0]:5432/app", doc.String())
+
+	conn, err := doc.Connection()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fe80::1%eth0", conn.Host)
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hostListPattern matches host:port pairs separated by commas, the bootstrap
+// string of Kafka clients: b1:9092,b2:9092 or [::1]:9092.
+var hostListPattern = regexp.MustCompile(`^\s*(?:\[[^\]]+\]|[\w.\-]+):\d+\s*(?:,\s*(?:\[[^\]]+\]|[\w.\-]+):\d+\s*)*$`)
+
+func detectHostList(p *parser, input string) float64 {
+	if hostListPattern.MatchString(input) {
+		return 0.6
+	}
+
+	return 0
+}
+
+// fromHostList reads a list of host:port pairs. A host without a port is
+// allowed, and spaces around the commas are ignored.
+func (p *parser) fromHostList(input string) (*connection, error) {
+	var endpoints []Endpoint
+	for _, address := range strings.Split(input, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			return nil, fmt.Errorf("parse %q: empty host", input)
+		}
+
+		host, port, _ := cutPort(address)
+		host = trimBrackets(host)
+
+		if host == "" || (port != "" && !isDigits(port)) {
+			return nil, fmt.Errorf("parse %q: %q is not a host and port", input, address)
+		}
+
+		endpoints = append(endpoints, Endpoint{Host: host, Port: port})
+	}
+
+	c := &connection{}
+	c.setEndpoints(endpoints)
+
+	return c, nil
+}
+
+// renderHostList writes the hosts alone; the other fields have no place in a
+// host list.
+func renderHostList(c *connection) (string, error) {
+	endpoints := c.Endpoints()
+	if len(endpoints) == 0 {
+		return "", errors.New("host list: the connection has no host")
+	}
+
+	addresses := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if isSocketPath(endpoint.Host) {
+			return "", errors.New("host list: a unix socket has no host list form")
+		}
+
+		addresses = append(addresses, hostPort(endpoint.Host, endpoint.Port))
+	}
+
+	return strings.Join(addresses, ","), nil
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostList(t *testing.T) {
+	checks := map[string][]Endpoint{
+		"b1:9092,b2:9092":                 {{Host: "b1", Port: "9092"}, {Host: "b2", Port: "9092"}},
+		"b1.example.com:9092, [::1]:9093": {{Host: "b1.example.com", Port: "9092"}, {Host: "::1", Port: "9093"}},
+		"broker_1:9092":                   {{Host: "broker_1", Port: "9092"}},
+	}
+
+	for input, expected := range checks {
+		t.Run(input, func(t *testing.T) {
+			assert.Equal(t, FormatHostList, Detect(input))
+
+			conn, err := Parse(input)
+
+			assert.NoError(t, err)
+			assert.Nil(t, conn.Type)
+			assert.Equal(t, expected, conn.Endpoints())
+		})
+	}
+
+	// a host without a port is only read when the format is named
+	assert.Equal(t, FormatPairs, Detect("b1,b2:9092"))
+
+	conn, err := ParseAs(FormatHostList, "b1,b2:9092")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Endpoint{{Host: "b1"}, {Host: "b2", Port: "9092"}}, conn.Endpoints())
+
+	_, err = ParseAs(FormatHostList, "b1:9092,,b2:9092")
+	assert.EqualError(t, err, `parse "b1:9092,,b2:9092": empty host`)
+
+	_, err = ParseAs(FormatHostList, "b1:x")
+	assert.EqualError(t, err, `parse "b1:x": "b1:x" is not a host and port`)
+}
+
+func TestRenderHostList(t *testing.T) {
+	conn, err := Parse("kafka://alice:secret@b1:9092,[::1]:9093,b3?tls=true")
+	assert.NoError(t, err)
+
+	rendered, err := conn.Render(FormatHostList)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "b1:9092,[::1]:9093,b3", rendered)
+
+	_, err = (&connection{}).Render(FormatHostList)
+	assert.EqualError(t, err, "host list: the connection has no host")
+
+	conn, err = Parse("redis://%2Ftmp%2Fredis.sock")
+	assert.NoError(t, err)
+
+	_, err = conn.Render(FormatHostList)
+	assert.EqualError(t, err, "host list: a unix socket has no host list form")
+}
+
+func TestURLList(t *testing.T) {
+	checks := map[string]struct {
+		input    string
+		hosts    []Endpoint
+		username string
+		database string
+		rendered string
+	}{
+		"nats": {
+			input:    "nats://n1:4222,nats://n2:4222",
+			hosts:    []Endpoint{{Host: "n1", Port: "4222"}, {Host: "n2", Port: "4222"}},
+			rendered: "nats://n1:4222,n2:4222",
+		},
+		"credentials on each url": {
+			input:    "nats://alice:secret@n1:4222,nats://alice:secret@n2,NATS://[::1]:4223/?name=app",
+			hosts:    []Endpoint{{Host: "n1", Port: "4222"}, {Host: "n2"}, {Host: "::1", Port: "4223"}},
+			username: "alice",
+			rendered: "nats://alice:secret@n1:4222,n2,[::1]:4223?name=app",
+		},
+		"path on the last url": {
+			input:    "kafka://b1:9092,kafka://b2:9092/events?acks=all",
+			hosts:    []Endpoint{{Host: "b1", Port: "9092"}, {Host: "b2", Port: "9092"}},
+			database: "events",
+			rendered: "kafka://b1:9092,b2:9092/events?acks=all",
+		},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			conn, err := Parse(check.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.hosts, conn.Endpoints())
+			assert.Equal(t, check.username, stringOf(conn.Username))
+			assert.Equal(t, check.database, conn.Database)
+
+			rendered, err := conn.Render(FormatURL)
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.rendered, rendered)
+		})
+	}
+
+	_, err := Parse("nats://n1:4222,tls://n2:4222")
+	assert.EqualError(t, err, `parse "nats://n1:4222,tls://n2:4222": "tls://n2:4222" does not start with nats://`)
+
+	_, err = Parse("nats://alice@n1:4222,nats://bob@n2:4222")
+	assert.EqualError(t, err, `parse "nats://alice@n1:4222,nats://bob@n2:4222": "nats://bob@n2:4222" has other credentials than the first URL`)
+
+	// a URL in a query value is not part of a list
+	conn, err := Parse("https://proxy.example.com/?targets=http://a,http://b")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy.example.com", conn.Host)
+	assert.Equal(t, "http://a,http://b", conn.GetProperty("targets"))
+
+	doc, err := ParseLossless("nats://alice@n1:4222,nats://n2:4222/app")
+	assert.NoError(t, err)
+
+	assert.EqualError(t, doc.Set("host", "n3"), "n1:4222,nats://n2:4222 names several hosts")
+	assert.NoError(t, doc.Set("database", "other"))
+	assert.NoError(t, doc.Set("username", "bob"))
+	assert.Equal(t, "nats://bob@n1:4222,nats://n2:4222/other", doc.String())
+}
@@ -0,0 +1,281 @@
+package parser
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+const jdbcPrefix = "jdbc:"
+
+var jdbcSqlServerAliases = map[string]string{
+	"servername":   keyHost,
+	"portnumber":   keyPort,
+	"databasename": keyDatabase,
+	"database":     keyDatabase,
+	"user":         keyUsername,
+	"username":     keyUsername,
+	"password":     keyPassword,
+}
+
+// jdbcSubprotocolOf names the JDBC subprotocol of the types that JDBC calls otherwise.
+var jdbcSubprotocolOf = map[string]string{
+	"postgres": "postgresql",
+}
+
+func detectJDBC(p *parser, input string) float64 {
+	if len(input) > len(jdbcPrefix) && strings.EqualFold(input[:len(jdbcPrefix)], jdbcPrefix) {
+		return 1
+	}
+
+	return 0
+}
+
+func (p *parser) fromJDBC(input string) (*connection, error) {
+	if detectJDBC(p, input) == 0 {
+		return nil, &SyntaxError{Offset: 0, Message: "missing \"jdbc:\" prefix"}
+	}
+
+	rest := input[len(jdbcPrefix):]
+	subprotocol, remainder, _ := strings.Cut(rest, ":")
+	offset := len(jdbcPrefix) + len(subprotocol) + 1
+
+	switch strings.ToLower(subprotocol) {
+	case "sqlserver":
+		return p.fromJDBCSqlServer(remainder, offset)
+	case "oracle":
+		return p.fromJDBCOracle(remainder, offset)
+	}
+
+	c, err := p.fromUrl(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	// JDBC drivers take the credentials from the query string
+	if user := c.GetProperties("user"); len(user) > 0 && c.Username == nil {
+		c.assign(keyUsername, user[0])
+		c.deleteProperty("user")
+	}
+
+	if password := c.GetProperties(keyPassword); len(password) > 0 && c.Password == nil {
+		c.assign(keyPassword, password[0])
+		c.deleteProperty(keyPassword)
+	}
+
+	return c, nil
+}
+
+// fromJDBCSqlServer reads jdbc:sqlserver://host\instance:port;key=value;...
+func (p *parser) fromJDBCSqlServer(input string, offset int) (*connection, error) {
+	address, options, _ := strings.Cut(input, ";")
+	if !strings.HasPrefix(address, "//") {
+		return nil, &SyntaxError{Offset: offset, Message: "expected \"//\" after jdbc:sqlserver:"}
+	}
+
+	optionsOffset := offset + len(address) + 1
+
+	c := &connection{}
+	c.assign(keyType, "sqlserver")
+
+	address = strings.TrimPrefix(address, "//")
+	if host, port, err := net.SplitHostPort(address); err == nil {
+		address = host
+		c.assign(keyPort, port)
+	}
+
+	if host, instance, ok := strings.Cut(address, `\`); ok {
+		address = host
+		c.addProperty("instance", instance)
+	}
+
+	c.Host = address
+
+	fields, err := p.adoNetParser().lexPairs(options)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		key, value, _ := field.split("=")
+		name := strings.TrimSpace(key.text())
+		if name == "" {
+			continue
+		}
+
+		if alias, ok := jdbcSqlServerAliases[strings.ToLower(name)]; ok {
+			name = alias
+		}
+
+		if _, err = p.assign(c, name, value.text()); err != nil {
+			return nil, &SyntaxError{Offset: optionsOffset + field.start, Message: err.Error()}
+		}
+	}
+
+	return c, nil
+}
+
+// fromJDBCOracle reads the thin driver forms jdbc:oracle:thin:@host:port:SID,
+// jdbc:oracle:thin:@//host:port/service and jdbc:oracle:thin:@(DESCRIPTION=...).
+func (p *parser) fromJDBCOracle(input string, offset int) (*connection, error) {
+	_, target, ok := strings.Cut(input, "@")
+	if !ok {
+		return nil, &SyntaxError{Offset: offset, Message: "expected \"@\" in jdbc:oracle URL"}
+	}
+
+	c := &connection{}
+	if strings.HasPrefix(strings.TrimSpace(target), "(") {
+		// jdbc:oracle:thin:@(DESCRIPTION=...)
+		c.assign(keyType, "oracle")
+		if err := applyTNSDescriptor(c, target, offset+len(input)-len(target)); err != nil {
+			return nil, err
+		}
+	} else if strings.HasPrefix(target, "//") {
+		parsed, err := p.fromUrl("oracle:" + target)
+		if err != nil {
+			return nil, err
+		}
+
+		c = parsed
+	} else {
+		c.assign(keyType, "oracle")
+
+		parts := strings.Split(target, ":")
+		c.Host = parts[0]
+		if len(parts) > 1 {
+			c.assign(keyPort, parts[1])
+		}
+
+		if len(parts) > 2 {
+			c.addProperty("sid", parts[2])
+		}
+	}
+
+	if credentials, _, _ := strings.Cut(input, "@"); strings.Contains(credentials, "/") {
+		// jdbc:oracle:thin:user/password@...
+		if i := strings.LastIndex(credentials, ":"); i >= 0 {
+			username, password, _ := strings.Cut(credentials[i+1:], "/")
+			c.assign(keyUsername, username)
+			c.assign(keyPassword, password)
+		}
+	}
+
+	return c, nil
+}
+
+func renderJDBC(c *connection) (string, error) {
+	if c.Type == nil {
+		return "", errors.New("jdbc: the connection has no type")
+	}
+
+	// the drivers only reach a socket through a third-party socket factory
+	if c.SocketPath() != "" {
+		return "", errors.New("jdbc: a unix socket has no JDBC form")
+	}
+
+	switch strings.ToLower(*c.Type) {
+	case "sqlserver":
+		if err := oneHost(c, "jdbc", "SQL Server JDBC"); err != nil {
+			return "", err
+		}
+
+		return renderJDBCSqlServer(c), nil
+	case "oracle":
+		if err := oneHost(c, "jdbc", "Oracle JDBC"); err != nil {
+			return "", err
+		}
+
+		return renderJDBCOracle(c), nil
+	}
+
+	// JDBC drivers take the credentials from the query string
+	query := c.clone()
+	query.Username, query.Password = nil, nil
+
+	if subprotocol, ok := jdbcSubprotocolOf[strings.ToLower(*c.Type)]; ok {
+		query.Type = &subprotocol
+	}
+
+	if c.Username != nil {
+		query.addProperty("user", *c.Username)
+	}
+
+	if c.Password != nil {
+		query.addProperty(keyPassword, *c.Password)
+	}
+
+	rendered, err := renderURL(query)
+	if err != nil {
+		return "", err
+	}
+
+	return jdbcPrefix + rendered, nil
+}
+
+func renderJDBCSqlServer(c *connection) string {
+	address := c.Host
+	if instance := c.GetProperty("instance"); instance != "" {
+		address += `\` + instance
+	}
+
+	if c.Port != "" {
+		address += ":" + c.Port
+	}
+
+	var b strings.Builder
+	b.WriteString(jdbcPrefix + "sqlserver://" + address)
+
+	write := func(key string, value string) {
+		b.WriteString(";" + key + "=" + quoteADONET(value))
+	}
+
+	if c.Database != "" {
+		write("databaseName", c.Database)
+	}
+
+	if c.Username != nil {
+		write("user", *c.Username)
+	}
+
+	if c.Password != nil {
+		write(keyPassword, *c.Password)
+	}
+
+	for _, property := range c.PropertyList() {
+		if property.Key != "instance" {
+			write(property.name(), property.Value)
+		}
+	}
+
+	return b.String()
+}
+
+func renderJDBCOracle(c *connection) string {
+	var b strings.Builder
+	b.WriteString(jdbcPrefix + "oracle:thin:")
+
+	if c.Username != nil || c.Password != nil {
+		b.WriteString(stringOf(c.Username) + "/" + stringOf(c.Password))
+	}
+
+	var properties []Property
+	sid := c.GetProperty("sid")
+	for _, property := range c.PropertyList() {
+		if property.Key != "sid" || c.Database != "" {
+			properties = append(properties, property)
+		}
+	}
+
+	if sid != "" && c.Database == "" {
+		// in host:port:SID the port cannot be left out
+		b.WriteString("@" + hostPort(c.Host, firstOf(c.Port, "1521")) + ":" + sid)
+	} else {
+		b.WriteString("@//" + hostPort(c.Host, c.Port) + "/" + c.Database)
+	}
+
+	if query := encodeQuery(properties); query != "" {
+		b.WriteString("?" + query)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var jdbcChecks = map[string]dataProvider{
+	"jdbc - postgresql with credentials in the query": {
+		input: "jdbc:postgresql://db.example.com:5432/app?user=alice&password=secret&ssl=true",
+		expected: &connection{
+			Type:        toPtr("postgresql"),
+			Username:    toPtr("alice"),
+			Password:    toPtr("secret"),
+			Host:        "db.example.com",
+			Port:        "5432",
+			NumericPort: 5432,
+			Database:    "app",
+			Properties: map[string][]string{
+				"ssl": {"true"},
+			},
+		},
+	},
+	"jdbc - user info wins over the query": {
+		input: "jdbc:mysql://root@db.example.com/app?user=other",
+		expected: &connection{
+			Type:     toPtr("mysql"),
+			Username: toPtr("root"),
+			Host:     "db.example.com",
+			Database: "app",
+			Properties: map[string][]string{
+				"user": {"other"},
+			},
+		},
+	},
+	"jdbc - sqlserver": {
+		input: `jdbc:sqlserver://db.example.com\SQLEXPRESS:1433;databaseName=app;user=sa;password={secret};encrypt=true`,
+		expected: &connection{
+			Type:        toPtr("sqlserver"),
+			Username:    toPtr("sa"),
+			Password:    toPtr("{secret}"),
+			Host:        "db.example.com",
+			Port:        "1433",
+			NumericPort: 1433,
+			Database:    "app",
+			Properties: map[string][]string{
+				"instance": {"SQLEXPRESS"},
+				"encrypt":  {"true"},
+			},
+		},
+	},
+	"jdbc - oracle sid": {
+		input: "jdbc:oracle:thin:scott/tiger@db.example.com:1521:orcl",
+		expected: &connection{
+			Type:        toPtr("oracle"),
+			Username:    toPtr("scott"),
+			Password:    toPtr("tiger"),
+			Host:        "db.example.com",
+			Port:        "1521",
+			NumericPort: 1521,
+			Properties: map[string][]string{
+				"sid": {"orcl"},
+			},
+		},
+	},
+	"jdbc - oracle service": {
+		input: "jdbc:oracle:thin:@//db.example.com:1521/service",
+		expected: &connection{
+			Type:        toPtr("oracle"),
+			Host:        "db.example.com",
+			Port:        "1521",
+			NumericPort: 1521,
+			Database:    "service",
+		},
+	},
+	"jdbc - sqlserver without slashes": {
+		input:        "jdbc:sqlserver:db.example.com",
+		expectsError: true,
+	},
+	"jdbc - oracle without target": {
+		input:        "jdbc:oracle:thin:db.example.com",
+		expectsError: true,
+	},
+	"jdbc - invalid url": {
+		input:        "jdbc:postgresql://%zz/app",
+		expectsError: true,
+	},
+}
+
+func TestJDBC(t *testing.T) {
+	for name, check := range jdbcChecks {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, FormatJDBC, Detect(check.input))
+
+			conn, err := Parse(check.input)
+
+			if check.expectsError {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assertConnection(t, check.expected, conn)
+		})
+	}
+}
+
+func TestJDBCWithoutPrefix(t *testing.T) {
+	_, err := ParseAs(FormatJDBC, "postgresql://example.com")
+
+	assert.EqualError(t, err, `syntax error at offset 0: missing "jdbc:" prefix`)
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const kafkaDefaultPort = "9092"
+
+var kafkaSecurityProtocols = []string{"PLAINTEXT", "SSL", "SASL_PLAINTEXT", "SASL_SSL"}
+
+var kafkaMechanisms = []string{"PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512", "GSSAPI", "OAUTHBEARER"}
+
+// KafkaOptions holds the broker list and the security settings of a Kafka
+// connection, read from the librdkafka property names.
+type KafkaOptions struct {
+	Brokers []Endpoint
+	// SecurityProtocol is one of PLAINTEXT, SSL, SASL_PLAINTEXT or SASL_SSL
+	SecurityProtocol string
+	TLS              bool
+	SASLMechanism    string
+	SASLUsername     string
+	SASLPassword     string
+	CALocation       string
+	CertLocation     string
+	KeyLocation      string
+}
+
+// Kafka reads a kafka:// connection, or a bare host list such as
+// b1:9092,b2:9092. security.protocol, sasl.mechanism, sasl.username,
+// sasl.password and the ssl.*.location paths are read from the properties,
+// and tls=true asks for TLS without naming the protocol.
+func (c *connection) Kafka() (*KafkaOptions, error) {
+	if c.Type != nil && !c.IsFor("kafka") {
+		return nil, fmt.Errorf("%s is not a Kafka connection", describeType(c))
+	}
+
+	o := &KafkaOptions{
+		CALocation:   c.GetProperty("ssl.ca.location"),
+		CertLocation: c.GetProperty("ssl.certificate.location"),
+		KeyLocation:  c.GetProperty("ssl.key.location"),
+	}
+
+	if err := o.readBrokers(c); err != nil {
+		return nil, err
+	}
+
+	if err := o.readSASL(c); err != nil {
+		return nil, err
+	}
+
+	if err := o.readSecurityProtocol(c); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *KafkaOptions) readBrokers(c *connection) error {
+	endpoints := c.Endpoints()
+	if len(endpoints) == 0 {
+		return errors.New("kafka has no broker")
+	}
+
+	var err error
+	o.Brokers, err = withDefaultPort(endpoints, kafkaDefaultPort)
+
+	return err
+}
+
+func (o *KafkaOptions) readSASL(c *connection) error {
+	if mechanism := c.GetProperty("sasl.mechanism", c.GetProperty("sasl.mechanisms")); mechanism != "" {
+		if o.SASLMechanism = canonicalOf(mechanism, kafkaMechanisms); o.SASLMechanism == "" {
+			return fmt.Errorf("sasl.mechanism %q is not one of %s", mechanism, strings.Join(kafkaMechanisms, ", "))
+		}
+	}
+
+	o.SASLUsername, o.SASLPassword = stringOf(c.Username), stringOf(c.Password)
+
+	if err := readKafkaCredential(c, "sasl.username", &o.SASLUsername); err != nil {
+		return err
+	}
+
+	if err := readKafkaCredential(c, "sasl.password", &o.SASLPassword); err != nil {
+		return err
+	}
+
+	// credentials alone mean the simplest mechanism; a mechanism alone is
+	// fine, as the client may be handed the credentials on its own
+	if o.SASLMechanism == "" && o.SASLUsername != "" {
+		o.SASLMechanism = "PLAIN"
+	}
+
+	return nil
+}
+
+func (o *KafkaOptions) readSecurityProtocol(c *connection) error {
+	tls, hasTLS := false, false
+	if v := c.GetProperty("tls"); v != "" {
+		parsed, ok := parseURIBool(v)
+		if !ok {
+			return fmt.Errorf("tls %q is not a boolean", v)
+		}
+
+		tls, hasTLS = parsed, true
+	}
+
+	if protocol := c.GetProperty("security.protocol"); protocol != "" {
+		if o.SecurityProtocol = canonicalOf(protocol, kafkaSecurityProtocols); o.SecurityProtocol == "" {
+			return fmt.Errorf("security.protocol %q is not one of %s", protocol, strings.Join(kafkaSecurityProtocols, ", "))
+		}
+	} else {
+		// the ssl.* paths ask for TLS as well, unless tls=false
+		o.SecurityProtocol = "PLAINTEXT"
+		if tls || (!hasTLS && o.CALocation+o.CertLocation+o.KeyLocation != "") {
+			o.SecurityProtocol = "SSL"
+		}
+
+		if o.SASLMechanism != "" {
+			o.SecurityProtocol = "SASL_" + o.SecurityProtocol
+		}
+	}
+
+	o.TLS = strings.HasSuffix(o.SecurityProtocol, "SSL")
+	if hasTLS && tls != o.TLS {
+		return fmt.Errorf("tls=%s and security.protocol %s disagree", c.GetProperty("tls"), o.SecurityProtocol)
+	}
+
+	sasl := strings.HasPrefix(o.SecurityProtocol, "SASL_")
+	if o.SASLMechanism != "" && !sasl {
+		return fmt.Errorf("sasl.mechanism %s needs security.protocol SASL_PLAINTEXT or SASL_SSL, not %s", o.SASLMechanism, o.SecurityProtocol)
+	}
+
+	// as in librdkafka, which defaults to Kerberos
+	if sasl && o.SASLMechanism == "" {
+		o.SASLMechanism = "GSSAPI"
+	}
+
+	return nil
+}
+
+// readKafkaCredential reads a sasl.username or sasl.password property into
+// value, which holds the user info; the two must agree when both are set.
+func readKafkaCredential(c *connection, key string, value *string) error {
+	if !c.HasProperty(key) {
+		return nil
+	}
+
+	v := c.GetProperty(key)
+	if *value != "" && *value != v {
+		return fmt.Errorf("%s disagrees with the user info", key)
+	}
+
+	*value = v
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafka(t *testing.T) {
+	checks := map[string]struct {
+		input    string
+		expected *KafkaOptions
+	}{
+		"bootstrap string": {
+			input: "b1:9092,b2:9093",
+			expected: &KafkaOptions{
+				Brokers:          []Endpoint{{Host: "b1", Port: "9092"}, {Host: "b2", Port: "9093"}},
+				SecurityProtocol: "PLAINTEXT",
+			},
+		},
+		"scram from the user info": {
+			input: "kafka://alice:secret@b1,b2:9093?sasl.mechanism=scram-sha-512&tls=true",
+			expected: &KafkaOptions{
+				Brokers:          []Endpoint{{Host: "b1", Port: "9092"}, {Host: "b2", Port: "9093"}},
+				SecurityProtocol: "SASL_SSL",
+				TLS:              true,
+				SASLMechanism:    "SCRAM-SHA-512",
+				SASLUsername:     "alice",
+				SASLPassword:     "secret",
+			},
+		},
+		"credentials in properties": {
+			input: "kafka://b1:9092?sasl.username=alice&sasl.password=secret",
+			expected: &KafkaOptions{
+				Brokers:          []Endpoint{{Host: "b1", Port: "9092"}},
+				SecurityProtocol: "SASL_PLAINTEXT",
+				SASLMechanism:    "PLAIN",
+				SASLUsername:     "alice",
+				SASLPassword:     "secret",
+			},
+		},
+		"mutual tls": {
+			input: "kafka://b1:9093?ssl.ca.location=/etc/ca.pem&ssl.certificate.location=/etc/c.pem&ssl.key.location=/etc/k.pem",
+			expected: &KafkaOptions{
+				Brokers:          []Endpoint{{Host: "b1", Port: "9093"}},
+				SecurityProtocol: "SSL",
+				TLS:              true,
+				CALocation:       "/etc/ca.pem",
+				CertLocation:     "/etc/c.pem",
+				KeyLocation:      "/etc/k.pem",
+			},
+		},
+		"kerberos by default": {
+			input: "kafka://b1?security.protocol=sasl_ssl",
+			expected: &KafkaOptions{
+				Brokers:          []Endpoint{{Host: "b1", Port: "9092"}},
+				SecurityProtocol: "SASL_SSL",
+				TLS:              true,
+				SASLMechanism:    "GSSAPI",
+			},
+		},
+		"mechanism without credentials": {
+			input: "kafka://b1:9092,b2:9092?sasl.mechanism=SCRAM-SHA-512",
+			expected: &KafkaOptions{
+				Brokers:          []Endpoint{{Host: "b1", Port: "9092"}, {Host: "b2", Port: "9092"}},
+				SecurityProtocol: "SASL_PLAINTEXT",
+				SASLMechanism:    "SCRAM-SHA-512",
+			},
+		},
+		"list of urls": {
+			input: "kafka://b1:9092,kafka://b2:9092?sasl.mechanism=OAUTHBEARER",
+			expected: &KafkaOptions{
+				Brokers:          []Endpoint{{Host: "b1", Port: "9092"}, {Host: "b2", Port: "9092"}},
+				SecurityProtocol: "SASL_PLAINTEXT",
+				SASLMechanism:    "OAUTHBEARER",
+			},
+		},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			conn, err := Parse(check.input)
+			assert.NoError(t, err)
+
+			options, err := conn.Kafka()
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.expected, options)
+		})
+	}
+}
+
+func TestKafkaErrors(t *testing.T) {
+	checks := map[string]string{
+		"kafka:///topic":                                "kafka has no broker",
+		"kafka://b1:99999":                              `port "99999" of host "b1" is not between 1 and 65535`,
+		"kafka://b1?sasl.mechanism=DIGEST-MD5":          `sasl.mechanism "DIGEST-MD5" is not one of PLAIN, SCRAM-SHA-256, SCRAM-SHA-512, GSSAPI, OAUTHBEARER`,
+		"kafka://alice:secret@b1?sasl.username=bob":     "sasl.username disagrees with the user info",
+		"kafka://b1?security.protocol=TLS":              `security.protocol "TLS" is not one of PLAINTEXT, SSL, SASL_PLAINTEXT, SASL_SSL`,
+		"kafka://b1?security.protocol=SSL&tls=false":    "tls=false and security.protocol SSL disagree",
+		"kafka://b1?tls=maybe":                          `tls "maybe" is not a boolean`,
+		"kafka://alice:secret@b1?security.protocol=SSL": "sasl.mechanism PLAIN needs security.protocol SASL_PLAINTEXT or SASL_SSL, not SSL",
+		"amqp://mq.example.com":                         `"amqp" is not a Kafka connection`,
+	}
+
+	for input, expected := range checks {
+		t.Run(input, func(t *testing.T) {
+			conn, err := Parse(input)
+			assert.NoError(t, err)
+
+			_, err = conn.Kafka()
+
+			assert.EqualError(t, err, expected)
+		})
+	}
+}
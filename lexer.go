@@ -0,0 +1,329 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var defaultQuotes = []rune{'"'}
+
+var errInvalidSeparator = errors.New("invalid key/value separator: it must not be empty")
+
+var errInvalidDelimiter = errors.New("invalid delimiter: it must be valid UTF-8 without line breaks, quote or escape characters")
+
+type SyntaxError struct {
+	Offset  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Offset, e.Message)
+}
+
+type segment struct {
+	text string
+	// quoted is set for text that came from quotes or an escape character,
+	// so it is never trimmed.
+	quoted bool
+	start  int
+	end    int
+}
+
+type pairField struct {
+	segments []segment
+	start    int
+	end      int
+}
+
+func (f pairField) text() string {
+	if len(f.segments) == 1 {
+		return f.segments[0].text
+	}
+
+	var b strings.Builder
+	for _, s := range f.segments {
+		b.WriteString(s.text)
+	}
+
+	return b.String()
+}
+
+func newPairField(segments []segment) pairField {
+	f := pairField{segments: segments}
+	if len(segments) > 0 {
+		f.start = segments[0].start
+		f.end = segments[len(segments)-1].end
+	}
+
+	return f
+}
+
+// split cuts the field at the first separator, which may also sit inside a
+// quoted segment — `"password=pass word"` is a pair.
+func (f pairField) split(separator string) (pairField, pairField, bool) {
+	for i, s := range f.segments {
+		at := strings.Index(s.text, separator)
+		if at < 0 {
+			continue
+		}
+
+		head, tail := s, s
+		head.text, tail.text = s.text[:at], s.text[at+len(separator):]
+		if !s.quoted {
+			head.end = s.start + at
+			tail.start = head.end + len(separator)
+		}
+
+		segments := make([]segment, len(f.segments)+1)
+		copy(segments, f.segments[:i])
+		segments[i], segments[i+1] = head, tail
+		copy(segments[i+2:], f.segments[i+1:])
+
+		return newPairField(segments[: i+1 : i+1]), newPairField(segments[i+1:]), true
+	}
+
+	return f, pairField{start: f.end, end: f.end}, false
+}
+
+func isLineBreak(r rune) bool {
+	return r == '\n' || r == '\r'
+}
+
+func (p *parser) isQuote(r rune) bool {
+	for _, quote := range p.quotes {
+		if r == quote {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) isEscape(r rune) bool {
+	return p.escape != 0 && r == p.escape
+}
+
+func (p *parser) validateLexer() error {
+	if p.separator == "" {
+		return errInvalidSeparator
+	}
+
+	if len(p.delimiters) == 0 {
+		return errInvalidDelimiter
+	}
+
+	for _, delimiter := range p.delimiters {
+		if delimiter == "" || !utf8.ValidString(delimiter) {
+			return errInvalidDelimiter
+		}
+
+		for _, r := range delimiter {
+			if r == 0 || r == utf8.RuneError || isLineBreak(r) || p.isQuote(r) || p.isEscape(r) {
+				return errInvalidDelimiter
+			}
+		}
+	}
+
+	return nil
+}
+
+// textBuf collects byte ranges of the input. As long as the ranges are
+// contiguous the text is a plain substring, so nothing is copied.
+type textBuf struct {
+	input   string
+	start   int
+	end     int
+	pending bool
+	copied  *strings.Builder
+}
+
+func (t *textBuf) add(from int, to int) {
+	switch {
+	case !t.pending:
+		t.start, t.end, t.pending = from, to, true
+	case t.copied == nil && from == t.end:
+		t.end = to
+	default:
+		if t.copied == nil {
+			t.copied = &strings.Builder{}
+			t.copied.WriteString(t.input[t.start:t.end])
+		}
+
+		t.copied.WriteString(t.input[from:to])
+		t.end = to
+	}
+}
+
+func (t *textBuf) String() string {
+	if t.copied != nil {
+		return t.copied.String()
+	}
+
+	return t.input[t.start:t.end]
+}
+
+func (t *textBuf) reset() {
+	t.pending = false
+	t.copied = nil
+}
+
+type pairLexer struct {
+	p      *parser
+	input  string
+	pos    int
+	fields []pairField
+	field  pairField
+	buf    textBuf
+}
+
+func (p *parser) lexPairs(input string) ([]pairField, error) {
+	if err := p.validateLexer(); err != nil {
+		return nil, err
+	}
+
+	l := &pairLexer{p: p, input: input, buf: textBuf{input: input}}
+	if err := l.run(); err != nil {
+		return nil, err
+	}
+
+	return l.fields, nil
+}
+
+func (l *pairLexer) next() (rune, int) {
+	r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+	start := l.pos
+	l.pos += size
+
+	return r, start
+}
+
+func (l *pairLexer) flush(end int) {
+	if l.buf.pending {
+		l.field.segments = append(l.field.segments, segment{text: l.buf.String(), start: l.buf.start, end: end})
+		l.buf.reset()
+	}
+}
+
+func (l *pairLexer) delimiter() int {
+	for _, delimiter := range l.p.delimiters {
+		if strings.HasPrefix(l.input[l.pos:], delimiter) {
+			return len(delimiter)
+		}
+	}
+
+	return 0
+}
+
+func (l *pairLexer) endField(end int) {
+	l.flush(end)
+	if l.p.collapseSpace {
+		l.field.segments = trimSegments(l.field.segments)
+	}
+
+	if len(l.field.segments) > 0 {
+		l.fields = append(l.fields, newPairField(l.field.segments))
+	}
+
+	l.field = pairField{}
+}
+
+func (l *pairLexer) run() error {
+	for l.pos < len(l.input) {
+		if size := l.delimiter(); size > 0 {
+			l.endField(l.pos)
+			l.pos += size
+			continue
+		}
+
+		r, at := l.next()
+
+		switch {
+		case isLineBreak(r):
+			l.endField(at)
+		case l.p.isQuote(r):
+			l.flush(at)
+			if err := l.quoted(r, at); err != nil {
+				return err
+			}
+		case l.p.isEscape(r):
+			if l.pos >= len(l.input) {
+				return &SyntaxError{Offset: at, Message: "escape character at end of input"}
+			}
+
+			// an escaped rune is literal text, just like a quoted one
+			l.flush(at)
+			_, escaped := l.next()
+			l.field.segments = append(l.field.segments, segment{text: l.input[escaped:l.pos], quoted: true, start: at, end: l.pos})
+		default:
+			l.buf.add(at, l.pos)
+		}
+	}
+
+	l.endField(l.pos)
+
+	return nil
+}
+
+func (l *pairLexer) quoted(quote rune, start int) error {
+	b := textBuf{input: l.input}
+	b.add(l.pos, l.pos)
+
+	for l.pos < len(l.input) {
+		r, at := l.next()
+
+		switch {
+		case l.p.isEscape(r):
+			if l.pos >= len(l.input) {
+				return &SyntaxError{Offset: at, Message: "escape character at end of input"}
+			}
+
+			_, escaped := l.next()
+			b.add(escaped, l.pos)
+		case r == quote:
+			// a doubled quote stands for the quote itself
+			if strings.HasPrefix(l.input[l.pos:], string(quote)) {
+				b.add(at, l.pos)
+				l.pos += utf8.RuneLen(quote)
+				continue
+			}
+
+			l.field.segments = append(l.field.segments, segment{text: b.String(), quoted: true, start: start, end: l.pos})
+			return nil
+		default:
+			b.add(at, l.pos)
+		}
+	}
+
+	return &SyntaxError{Offset: start, Message: fmt.Sprintf("unterminated %c quote", quote)}
+}
+
+func trimSegments(segments []segment) []segment {
+	for len(segments) > 0 && !segments[0].quoted {
+		s := &segments[0]
+		trimmed := strings.TrimLeftFunc(s.text, unicode.IsSpace)
+		s.start += len(s.text) - len(trimmed)
+		s.text = trimmed
+		if trimmed != "" {
+			break
+		}
+
+		segments = segments[1:]
+	}
+
+	for len(segments) > 0 && !segments[len(segments)-1].quoted {
+		s := &segments[len(segments)-1]
+		trimmed := strings.TrimRightFunc(s.text, unicode.IsSpace)
+		s.end -= len(s.text) - len(trimmed)
+		s.text = trimmed
+		if trimmed != "" {
+			break
+		}
+
+		segments = segments[:len(segments)-1]
+	}
+
+	return segments
+}
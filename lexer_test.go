@@ -0,0 +1,314 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lexerCase struct {
+	input    string
+	parser   *parser
+	expected *connection
+	offset   int
+}
+
+var lexerChecks = map[string]lexerCase{
+	"multi-line input keeps every line": {
+		input: "host=example.com\nport=5432\r\nuser=alice\n\ndb=app",
+		expected: &connection{
+			Username:    toPtr("alice"),
+			Host:        "example.com",
+			Port:        "5432",
+			NumericPort: 5432,
+			Database:    "app",
+		},
+	},
+	"quoted value after the key": {
+		input: `host=example.com password="pass word"`,
+		expected: &connection{
+			Password: toPtr("pass word"),
+			Host:     "example.com",
+		},
+	},
+	"doubled quote is a literal quote": {
+		input: `password="say ""hi"""`,
+		expected: &connection{
+			Password: toPtr(`say "hi"`),
+		},
+	},
+	"quoted empty field is skipped": {
+		input: `host=example.com ""`,
+		expected: &connection{
+			Host: "example.com",
+		},
+	},
+	"quoted line break": {
+		input:  "password='multi\nline' host=example.com",
+		parser: NewParser().Quotes('\''),
+		expected: &connection{
+			Password: toPtr("multi\nline"),
+			Host:     "example.com",
+		},
+	},
+	"several quote characters": {
+		input:  `password='it"s' user="o'neil"`,
+		parser: NewParser().Quotes('\'', '"'),
+		expected: &connection{
+			Username: toPtr("o'neil"),
+			Password: toPtr(`it"s`),
+		},
+	},
+	"no quote characters": {
+		input:  `password="secret"`,
+		parser: NewParser().Quotes(),
+		expected: &connection{
+			Password: toPtr(`"secret"`),
+		},
+	},
+	"escape outside quotes": {
+		input:  `password=pass\ word host=example.com`,
+		parser: NewParser().Escape('\\'),
+		expected: &connection{
+			Password: toPtr("pass word"),
+			Host:     "example.com",
+		},
+	},
+	"escape inside quotes": {
+		input:  `password='it\'s \\ here'`,
+		parser: NewParser().Quotes('\'').Escape('\\'),
+		expected: &connection{
+			Password: toPtr(`it's \ here`),
+		},
+	},
+	"backslash is literal without an escape character": {
+		input: `password=a\b`,
+		expected: &connection{
+			Password: toPtr(`a\b`),
+		},
+	},
+	"multi-byte runes are kept": {
+		input:  "user=jöhn;password=pässwörd",
+		parser: NewParser().Delimiter(';'),
+		expected: &connection{
+			Username: toPtr("jöhn"),
+			Password: toPtr("pässwörd"),
+		},
+	},
+	"multi-byte delimiter": {
+		input:  "user=alice→host=example.com",
+		parser: NewParser().Delimiter('→'),
+		expected: &connection{
+			Username: toPtr("alice"),
+			Host:     "example.com",
+		},
+	},
+	"string delimiter": {
+		input:  "host=a; port=5432; user=b",
+		parser: NewParser().Delimiters("; "),
+		expected: &connection{
+			Username:    toPtr("b"),
+			Host:        "a",
+			Port:        "5432",
+			NumericPort: 5432,
+		},
+	},
+	"longest delimiter wins": {
+		input:  "host=a, port=5432,user=b",
+		parser: NewParser().Delimiters(",", ", "),
+		expected: &connection{
+			Username:    toPtr("b"),
+			Host:        "a",
+			Port:        "5432",
+			NumericPort: 5432,
+		},
+	},
+	"set of delimiter runes": {
+		input:  "host=a;port=5432\tuser=b c=d",
+		parser: NewParser().Delimiters(";", "\t", " "),
+		expected: &connection{
+			Username:    toPtr("b"),
+			Host:        "a",
+			Port:        "5432",
+			NumericPort: 5432,
+			Properties: map[string][]string{
+				"c": {"d"},
+			},
+		},
+	},
+	"surrounding whitespace is kept by default": {
+		input:  "host=a ;port=5432; user=b",
+		parser: NewParser().Delimiter(';'),
+		expected: &connection{
+			Username:    toPtr("b"),
+			Host:        "a ",
+			Port:        "5432",
+			NumericPort: 5432,
+		},
+	},
+	"collapsed surrounding whitespace": {
+		input:  "host=a ; port=5432;  user=b\t;\t ;sslmode=\" x \" ;password=\\ y\\ ",
+		parser: NewParser().Delimiter(';').Escape('\\').CollapseSpace(true),
+		expected: &connection{
+			Username:    toPtr("b"),
+			Password:    toPtr(" y "),
+			Host:        "a",
+			Port:        "5432",
+			NumericPort: 5432,
+			Properties: map[string][]string{
+				"sslmode": {" x "},
+			},
+		},
+	},
+	"colon separator": {
+		input:  "host: example.com; port: 5432; user:alice",
+		parser: NewParser().Delimiter(';').KeyValueSeparator(":"),
+		expected: &connection{
+			Username:    toPtr("alice"),
+			Host:        " example.com",
+			Port:        " 5432",
+			NumericPort: 0,
+		},
+	},
+	"arrow separator with trimmed values": {
+		input:  "host => example.com\nport => 5432\npassword => \"  spaced  \"\nflag",
+		parser: NewParser().Delimiter(';').KeyValueSeparator("=>").TrimValues(true),
+		expected: &connection{
+			Password:    toPtr("  spaced  "),
+			Host:        "example.com",
+			Port:        "5432",
+			NumericPort: 5432,
+			Properties: map[string][]string{
+				"flag": {""},
+			},
+		},
+	},
+	"trimmed values keep inner and escaped space": {
+		input:  "password = pass word \\ ;user=  alice",
+		parser: NewParser().Delimiter(';').Escape('\\').TrimValues(true),
+		expected: &connection{
+			Username: toPtr("alice"),
+			Password: toPtr("pass word  "),
+		},
+	},
+	"separator inside a quoted pair": {
+		input:  `"host: example.com" "password: a: b"`,
+		parser: NewParser().KeyValueSeparator(": "),
+		expected: &connection{
+			Password: toPtr("a: b"),
+			Host:     "example.com",
+		},
+	},
+	"unterminated quote reports its offset": {
+		input:  `host=example.com password="secret`,
+		offset: 26,
+	},
+	"unterminated quote after multi-byte runes": {
+		input:  `user=jöhn password='secret`,
+		parser: NewParser().Quotes('\''),
+		offset: 20,
+	},
+	"escape at end of input": {
+		input:  `password=secret\`,
+		parser: NewParser().Escape('\\'),
+		offset: 15,
+	},
+	"escape at end of quoted input": {
+		input:  `password="secret\`,
+		parser: NewParser().Escape('\\'),
+		offset: 16,
+	},
+}
+
+func TestPairLexer(t *testing.T) {
+	for name, check := range lexerChecks {
+		t.Run(name, func(t *testing.T) {
+			p := check.parser
+			if p == nil {
+				p = NewParser()
+			}
+
+			conn, err := p.FromPair(check.input)
+
+			if check.expected == nil {
+				var syntaxErr *SyntaxError
+				assert.True(t, errors.As(err, &syntaxErr), "expected a *SyntaxError, got %v", err)
+				assert.Equal(t, check.offset, syntaxErr.Offset)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assertConnection(t, check.expected, conn)
+		})
+	}
+}
+
+func TestPairLexerRejectsInvalidDelimiters(t *testing.T) {
+	for _, p := range []*parser{
+		NewParser().Delimiter(0),
+		NewParser().Delimiter('\n'),
+		NewParser().Delimiter('"'),
+		NewParser().Delimiter('\\').Escape('\\'),
+		NewParser().Delimiter(0xD800),
+		NewParser().Delimiters(),
+		NewParser().Delimiters(";", ""),
+		NewParser().Delimiters("\xff"),
+		NewParser().Delimiters(";\n"),
+	} {
+		_, err := p.FromPair("host=example.com")
+
+		assert.ErrorIs(t, err, errInvalidDelimiter)
+	}
+
+	_, err := NewParser().KeyValueSeparator("").FromPair("host=example.com")
+	assert.ErrorIs(t, err, errInvalidSeparator)
+}
+
+func TestPairFieldSplit(t *testing.T) {
+	fields, err := NewParser().lexPairs(`password=a"b c"d flag "user=x"`)
+	assert.NoError(t, err)
+
+	key, value, ok := fields[0].split("=")
+	assert.True(t, ok)
+	assert.Equal(t, newPairField([]segment{{text: "password", start: 0, end: 8}}), key)
+	assert.Equal(t, newPairField([]segment{{text: "a", start: 9, end: 10}, {text: "b c", quoted: true, start: 10, end: 15}, {text: "d", start: 15, end: 16}}), value)
+
+	key, value, ok = fields[1].split("=")
+	assert.False(t, ok)
+	assert.Equal(t, "flag", key.text())
+	assert.Equal(t, pairField{start: 21, end: 21}, value)
+
+	// a separator inside quotes cannot be pinned to an offset, so both halves keep the quoted span
+	key, value, ok = fields[2].split("=")
+	assert.True(t, ok)
+	assert.Equal(t, newPairField([]segment{{text: "user", quoted: true, start: 22, end: 30}}), key)
+	assert.Equal(t, newPairField([]segment{{text: "x", quoted: true, start: 22, end: 30}}), value)
+}
+
+func TestPairLexerSpans(t *testing.T) {
+	fields, err := NewParser().lexPairs(`host=h  "pass word"=x`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []pairField{
+		{segments: []segment{{text: "host=h", start: 0, end: 6}}, start: 0, end: 6},
+		{segments: []segment{{text: "pass word", quoted: true, start: 8, end: 19}, {text: "=x", start: 19, end: 21}}, start: 8, end: 21},
+	}, fields)
+}
+
+func TestPairLexerCollapsedSpans(t *testing.T) {
+	fields, err := NewParser().Delimiter(';').CollapseSpace(true).lexPairs(`  a=1  ;  ; b="2" `)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []pairField{
+		{segments: []segment{{text: "a=1", start: 2, end: 5}}, start: 2, end: 5},
+		{segments: []segment{{text: "b=", start: 12, end: 14}, {text: "2", quoted: true, start: 14, end: 17}}, start: 12, end: 17},
+	}, fields)
+}
+
+func TestSyntaxErrorMessage(t *testing.T) {
+	_, err := Parse(`password="secret`)
+
+	assert.EqualError(t, err, "syntax error at offset 9: unterminated \" quote")
+}
@@ -0,0 +1,628 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span is a byte range of the input, End excluded.
+type Span struct {
+	Start int
+	End   int
+}
+
+type NodeKind string
+
+const NodeScheme NodeKind = "scheme"
+const NodeUserinfo NodeKind = "userinfo"
+const NodeUsername NodeKind = "username"
+const NodePassword NodeKind = "password"
+const NodeHost NodeKind = "host"
+const NodePort NodeKind = "port"
+const NodePath NodeKind = "path"
+const NodeKey NodeKind = "key"
+const NodeValue NodeKind = "value"
+
+// Node is one component of a lossless parse. Key is the field or property the
+// node belongs to, and Text is its decoded value.
+type Node struct {
+	Kind NodeKind
+	Key  string
+	Text string
+	Span Span
+}
+
+// Document is a connection string that remembers where every component
+// sits, so it can be edited without touching the rest of the text.
+type Document struct {
+	parser *parser
+	format Format
+	input  string
+	nodes  []Node
+	url    *urlSpans
+	pairs  []docPair
+}
+
+type urlSpans struct {
+	scheme    *Span
+	authority *Span
+	username  *Span
+	password  *Span
+	// host is the host without brackets, hostport the host as written
+	host     Span
+	hostport Span
+	port     *Span
+	path     Span
+	opaque   bool
+	file     bool
+	amqp     bool
+	list     bool
+	urls     bool
+	query    *Span
+}
+
+// docPair is a key/value pair: a pair of the delimited form, or of a URL query.
+type docPair struct {
+	key       string
+	name      string
+	span      Span
+	keySpan   Span
+	value     Span
+	separated bool
+}
+
+var schemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.\-]*$`)
+
+func (p *parser) ParseLossless(input string) (*Document, error) {
+	return p.ParseLosslessAs(p.Detect(input), input)
+}
+
+func (p *parser) ParseLosslessAs(name Format, input string) (*Document, error) {
+	if name != FormatURL && name != FormatPairs {
+		return nil, fmt.Errorf("format %q has no lossless mode", name)
+	}
+
+	// the regular parser reports the errors, so both modes agree on them
+	if _, err := p.parseAs(name, input); err != nil {
+		return nil, err
+	}
+
+	d := &Document{parser: p, format: name, input: input}
+	if name == FormatURL {
+		d.scanURL()
+	} else {
+		d.scanPairs()
+	}
+
+	return d, nil
+}
+
+func ParseLossless(input string) (*Document, error) {
+	return defaultParser.ParseLossless(input)
+}
+
+func ParseLosslessAs(name Format, input string) (*Document, error) {
+	return defaultParser.ParseLosslessAs(name, input)
+}
+
+func (d *Document) Format() Format {
+	return d.format
+}
+
+func (d *Document) String() string {
+	return d.input
+}
+
+func (d *Document) Nodes() []Node {
+	return append([]Node(nil), d.nodes...)
+}
+
+// Connection parses the current text of the document.
+func (d *Document) Connection() (*connection, error) {
+	return d.parser.resolve(d.parser.parseAs(d.format, d.input))
+}
+
+// Set changes a field (type, username, password, host, port, database) or a
+// property. Only the bytes of that component are rewritten; a component that
+// is missing is added. A repeated field is changed where the parser reads it
+// from, which is its last occurrence, and a repeated property at its first.
+func (d *Document) Set(key string, value string) error {
+	if d.format == FormatURL {
+		return d.setURL(d.parser.canonical(key), value)
+	}
+
+	return d.setPair(key, value)
+}
+
+func (d *Document) replace(span Span, text string) error {
+	updated, err := d.parser.ParseLosslessAs(d.format, d.input[:span.Start]+text+d.input[span.End:])
+	if err != nil {
+		return err
+	}
+
+	*d = *updated
+
+	return nil
+}
+
+func (d *Document) insert(at int, text string) error {
+	return d.replace(Span{Start: at, End: at}, text)
+}
+
+func (d *Document) add(kind NodeKind, key string, text string, span Span) {
+	d.nodes = append(d.nodes, Node{Kind: kind, Key: key, Text: text, Span: span})
+}
+
+func (d *Document) text(span Span) string {
+	return d.input[span.Start:span.End]
+}
+
+// scanURL finds the components the same way net/url splits a URL.
+func (d *Document) scanURL() {
+	u := &urlSpans{}
+	d.url = u
+
+	end := len(d.input)
+	if i := strings.IndexByte(d.input, '#'); i >= 0 {
+		end = i
+	}
+
+	offset := 0
+	if i := schemeEnd(d.input[:end]); i > 0 {
+		u.scheme = &Span{Start: 0, End: i}
+		d.add(NodeScheme, keyType, d.input[:i], *u.scheme)
+		offset = i + 1
+	}
+
+	pathEnd := end
+	if i := strings.IndexByte(d.input[offset:end], '?'); i >= 0 {
+		pathEnd = offset + i
+		u.query = &Span{Start: pathEnd + 1, End: end}
+	}
+
+	rest := d.input[offset:pathEnd]
+	u.amqp = u.scheme != nil && isAMQPScheme(d.text(*u.scheme))
+	if u.scheme != nil && isFileScheme(d.text(*u.scheme)) {
+		// a file URL has no authority, the rest is the path of the file
+		u.file = true
+	} else if strings.HasPrefix(rest, "//") && (u.scheme != nil || !strings.HasPrefix(rest, "///")) {
+		start := offset + 2
+		authorityEnd := pathEnd
+		if i := strings.IndexByte(d.input[start:pathEnd], '/'); i >= 0 {
+			authorityEnd = start + i
+		}
+
+		// nats://n1:4222,nats://n2:4222 is one authority with a list of hosts
+		if i := urlListEnd(d.input[:end]); i > 0 {
+			authorityEnd, u.urls = i, true
+		}
+
+		u.authority = &Span{Start: start, End: authorityEnd}
+		d.scanAuthority(*u.authority)
+		offset = authorityEnd
+	} else if u.scheme != nil && !strings.HasPrefix(rest, "/") {
+		u.opaque = true
+	}
+
+	u.path = Span{Start: offset, End: pathEnd}
+	if !u.opaque {
+		path := d.text(u.path)
+		if u.file {
+			_, path = splitFilePath(d.text(*u.scheme), path)
+		}
+
+		if unescaped, err := url.PathUnescape(path); err == nil {
+			path = unescaped
+		}
+
+		switch {
+		case u.amqp:
+			path = strings.TrimPrefix(path, "/")
+		case !u.file:
+			path = strings.TrimLeft(path, "/")
+		}
+
+		d.add(NodePath, keyDatabase, path, u.path)
+	}
+
+	if u.query != nil {
+		d.scanQuery(*u.query)
+	}
+}
+
+func (d *Document) scanAuthority(authority Span) {
+	u := d.url
+	hostStart := authority.Start
+
+	// in a list of URLs, the user info is that of the first one
+	text := d.text(authority)
+	if u.urls {
+		text, _, _ = strings.Cut(text, ",")
+	}
+
+	if at := strings.LastIndexByte(text, '@'); at >= 0 {
+		userinfo := Span{Start: authority.Start, End: authority.Start + at}
+		d.add(NodeUserinfo, "", d.text(userinfo), userinfo)
+
+		username := userinfo
+		if colon := strings.IndexByte(d.text(userinfo), ':'); colon >= 0 {
+			username.End = userinfo.Start + colon
+			u.password = &Span{Start: username.End + 1, End: userinfo.End}
+		}
+
+		u.username = &username
+		d.add(NodeUsername, keyUsername, unescapeOr(d.text(username), url.PathUnescape), username)
+		if u.password != nil {
+			d.add(NodePassword, keyPassword, unescapeOr(d.text(*u.password), url.PathUnescape), *u.password)
+		}
+
+		hostStart = userinfo.End + 1
+	}
+
+	u.hostport = Span{Start: hostStart, End: authority.End}
+	u.host = u.hostport
+	hostport := d.text(u.hostport)
+
+	// a list of hosts is one node: mongodb://h1:27017,h2:27018
+	if strings.Contains(hostport, ",") {
+		u.list = true
+		d.add(NodeHost, keyHost, hostport, u.hostport)
+		return
+	}
+
+	portStart := -1
+	if strings.HasPrefix(hostport, "[") {
+		if i := strings.IndexByte(hostport, ']'); i >= 0 {
+			u.host = Span{Start: hostStart + 1, End: hostStart + i}
+			u.hostport.End = hostStart + i + 1
+			if strings.HasPrefix(hostport[i+1:], ":") {
+				portStart = hostStart + i + 2
+			}
+		}
+	} else if i := strings.LastIndexByte(hostport, ':'); i >= 0 {
+		u.host.End = hostStart + i
+		u.hostport.End = u.host.End
+		portStart = hostStart + i + 1
+	}
+
+	d.add(NodeHost, keyHost, unescapeOr(d.text(u.host), url.PathUnescape), u.host)
+	if portStart >= 0 {
+		u.port = &Span{Start: portStart, End: authority.End}
+		d.add(NodePort, keyPort, d.text(*u.port), *u.port)
+	}
+}
+
+func (d *Document) scanQuery(query Span) {
+	scanQuery(d.text(query), func(pair queryPair) {
+		// url.Query drops these, so there is nothing to edit
+		if pair.err != nil {
+			return
+		}
+
+		span := Span{Start: query.Start + pair.offset, End: query.Start + pair.offset + len(pair.text)}
+		keySpan := Span{Start: span.Start, End: span.Start + len(pair.raw)}
+		value := Span{Start: keySpan.End, End: span.End}
+		if pair.separated {
+			value.Start++
+		}
+
+		d.pairs = append(d.pairs, docPair{key: pair.key, name: pair.key, span: span, keySpan: keySpan, value: value, separated: pair.separated})
+		d.add(NodeKey, pair.key, pair.key, keySpan)
+		d.add(NodeValue, pair.key, pair.value, value)
+	})
+}
+
+func (d *Document) scanPairs() {
+	// the regular parse went through, so lexing cannot fail here
+	fields, _ := d.parser.lexPairs(d.input)
+
+	for _, field := range fields {
+		if field.text() == "" {
+			continue
+		}
+
+		key, value, separated := field.split(d.parser.separator)
+		valueStart := value.start
+
+		key = newPairField(trimSegments(key.segments))
+		if d.parser.trimValues {
+			value = newPairField(trimSegments(value.segments))
+		}
+
+		if len(value.segments) == 0 {
+			value = pairField{start: valueStart, end: valueStart}
+		}
+
+		name := strings.TrimSpace(key.text())
+		canonical := d.parser.canonical(name)
+		keySpan := Span{Start: key.start, End: key.end}
+		valueSpan := Span{Start: value.start, End: value.end}
+
+		d.pairs = append(d.pairs, docPair{
+			key:     canonical,
+			name:    name,
+			span:    Span{Start: field.start, End: field.end},
+			keySpan: keySpan,
+			value:   valueSpan,
+			// a quoted "key=value" shares one span, and has to be written whole
+			separated: separated && valueSpan.Start >= keySpan.End,
+		})
+
+		d.add(NodeKey, canonical, name, keySpan)
+		d.add(NodeValue, canonical, value.text(), valueSpan)
+	}
+}
+
+func (d *Document) setURL(key string, value string) error {
+	u := d.url
+
+	if u.list && (key == keyHost || key == keyPort) {
+		return fmt.Errorf("%s names several hosts", d.text(u.hostport))
+	}
+
+	switch key {
+	case keyType:
+		if !schemePattern.MatchString(value) {
+			return fmt.Errorf("invalid scheme %q", value)
+		}
+
+		if u.scheme != nil {
+			return d.replace(*u.scheme, value)
+		}
+
+		return d.insert(0, value+":")
+	case keyUsername:
+		username := url.User(value).String()
+		if u.username != nil {
+			return d.replace(*u.username, username)
+		}
+
+		if u.authority != nil {
+			return d.insert(u.authority.Start, username+"@")
+		}
+	case keyPassword:
+		password := strings.TrimPrefix(url.UserPassword("", value).String(), ":")
+		switch {
+		case u.password != nil:
+			return d.replace(*u.password, password)
+		case u.username != nil:
+			return d.insert(u.username.End, ":"+password)
+		case u.authority != nil:
+			return d.insert(u.authority.Start, ":"+password+"@")
+		}
+	case keyHost:
+		switch {
+		case isSocketPath(value):
+			// a socket path is written the way cutSocketHost reads it
+			value = url.PathEscape(value)
+		case strings.ContainsAny(value, "/?#@[] "):
+			return fmt.Errorf("invalid host %q", value)
+		case strings.Contains(value, ":"):
+			// the zone of an IPv6 address is escaped: [fe80::1%25eth0]
+			value = "[" + strings.ReplaceAll(value, "%", "%25") + "]"
+		}
+
+		if u.authority != nil {
+			return d.replace(u.hostport, value)
+		}
+	case keyPort:
+		if strings.TrimFunc(value, unicode.IsDigit) != "" {
+			return fmt.Errorf("port %q is not a number", value)
+		}
+
+		if u.port != nil {
+			return d.replace(*u.port, value)
+		}
+
+		if u.authority != nil {
+			return d.insert(u.hostport.End, ":"+value)
+		}
+	case keyDatabase:
+		if u.file {
+			return d.replace(u.path, fileURLPath("", value))
+		}
+
+		if u.opaque {
+			return fmt.Errorf("%s:%s has no path to set", d.text(*u.scheme), d.text(u.path))
+		}
+
+		path := ""
+		switch {
+		case u.amqp && value != "":
+			// the vhost is a single segment, so its slashes are escaped
+			path = "/" + url.PathEscape(value)
+		case value != "":
+			path = (&url.URL{Path: "/" + value}).EscapedPath()
+		}
+
+		return d.replace(u.path, path)
+	default:
+		return d.setQuery(key, value)
+	}
+
+	return fmt.Errorf("cannot set %s: the URL has no authority", key)
+}
+
+func (d *Document) setQuery(key string, value string) error {
+	encoded := url.QueryEscape(value)
+
+	for _, pair := range d.pairs {
+		if pair.key != key {
+			continue
+		}
+
+		if !pair.separated {
+			encoded = "=" + encoded
+		}
+
+		return d.replace(pair.value, encoded)
+	}
+
+	pair := url.QueryEscape(key) + "=" + encoded
+	if u := d.url; u.query != nil {
+		if query := d.text(*u.query); query != "" && !strings.HasSuffix(query, "&") {
+			pair = "&" + pair
+		}
+
+		return d.insert(u.query.End, pair)
+	}
+
+	return d.insert(d.url.path.End, "?"+pair)
+}
+
+func (d *Document) setPair(key string, value string) error {
+	p := d.parser
+	canonical := p.canonical(key)
+
+	encoded, err := p.quoteValue(value)
+	if err != nil {
+		return err
+	}
+
+	found := -1
+	for i, pair := range d.pairs {
+		if pair.key == canonical {
+			found = i
+			if !isField(canonical) {
+				break
+			}
+		}
+	}
+
+	if found >= 0 {
+		pair := d.pairs[found]
+		if pair.separated {
+			return d.replace(pair.value, encoded)
+		}
+
+		name, err := p.quoteKey(pair.name)
+		if err != nil {
+			return err
+		}
+
+		return d.replace(pair.span, name+p.separator+encoded)
+	}
+
+	name, err := p.quoteKey(key)
+	if err != nil {
+		return err
+	}
+
+	text := name + p.separator + encoded
+	if d.input != "" && !d.endsWithDelimiter() {
+		text = p.delimiters[0] + text
+	}
+
+	return d.insert(len(d.input), text)
+}
+
+func (d *Document) endsWithDelimiter() bool {
+	for _, delimiter := range d.parser.delimiters {
+		if strings.HasSuffix(d.input, delimiter) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quoteValue writes a value so that the parser reads it back unchanged.
+func (p *parser) quoteValue(value string) (string, error) {
+	if strings.ContainsAny(value, "\r\n") {
+		return "", errors.New("a value cannot hold a line break")
+	}
+
+	if !p.needsQuotes(value) {
+		return value, nil
+	}
+
+	if len(p.quotes) > 0 {
+		quote, quoted := string(p.quotes[0]), value
+		if p.escape != 0 {
+			quoted = strings.ReplaceAll(quoted, string(p.escape), string(p.escape)+string(p.escape))
+		}
+
+		quoted = strings.ReplaceAll(quoted, quote, quote+quote)
+
+		return quote + quoted + quote, nil
+	}
+
+	if p.escape == 0 {
+		return "", fmt.Errorf("value %q needs quotes, but the parser has no quote or escape character", value)
+	}
+
+	var b strings.Builder
+	for _, r := range value {
+		if p.isEscape(r) || unicode.IsSpace(r) || p.isDelimiterRune(r) {
+			b.WriteRune(p.escape)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String(), nil
+}
+
+func (p *parser) quoteKey(key string) (string, error) {
+	if key == "" || strings.Contains(key, p.separator) {
+		return "", fmt.Errorf("key %q cannot be written", key)
+	}
+
+	return p.quoteValue(key)
+}
+
+func (p *parser) needsQuotes(value string) bool {
+	for _, r := range value {
+		if p.isQuote(r) || p.isEscape(r) || p.isDelimiterRune(r) {
+			return true
+		}
+	}
+
+	first, _ := utf8.DecodeRuneInString(value)
+	last, _ := utf8.DecodeLastRuneInString(value)
+
+	return value != "" && (unicode.IsSpace(first) || unicode.IsSpace(last))
+}
+
+func (p *parser) isDelimiterRune(r rune) bool {
+	for _, delimiter := range p.delimiters {
+		if strings.ContainsRune(delimiter, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// schemeEnd returns the length of the scheme at the start of the input, or 0.
+func schemeEnd(input string) int {
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' || c == '+' || c == '-' || c == '.':
+			if i == 0 {
+				return 0
+			}
+		case c == ':':
+			return i
+		default:
+			return 0
+		}
+	}
+
+	return 0
+}
+
+func unescapeOr(s string, unescape func(string) (string, error)) string {
+	if unescaped, err := unescape(s); err == nil {
+		return unescaped
+	}
+
+	return s
+}
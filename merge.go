@@ -0,0 +1,169 @@
+package parser
+
+func copyPtr(v *string) *string {
+	if v == nil {
+		return nil
+	}
+
+	s := *v
+	return &s
+}
+
+func (c *connection) clone() *connection {
+	cloned := &connection{
+		Type:        copyPtr(c.Type),
+		Username:    copyPtr(c.Username),
+		Password:    copyPtr(c.Password),
+		Host:        c.Host,
+		Port:        c.Port,
+		NumericPort: c.NumericPort,
+		Hosts:       append([]Endpoint(nil), c.Hosts...),
+		Database:    c.Database,
+		Path:        c.Path,
+	}
+
+	if len(c.Properties) > 0 {
+		cloned.Properties = make(map[string][]string, len(c.Properties))
+		for key, values := range c.Properties {
+			cloned.Properties[key] = append([]string(nil), values...)
+		}
+
+		cloned.properties = append([]Property(nil), c.properties...)
+	}
+
+	for field, origin := range c.origins {
+		cloned.setOrigin(field, origin)
+	}
+
+	return cloned
+}
+
+func (c *connection) setOrigin(field string, origin string) {
+	if origin == "" {
+		delete(c.origins, field)
+		if len(c.origins) == 0 {
+			c.origins = nil
+		}
+
+		return
+	}
+
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+
+	c.origins[field] = origin
+}
+
+func (c *connection) fields() []string {
+	var fields []string
+
+	if c.Type != nil {
+		fields = append(fields, keyType)
+	}
+
+	if c.Username != nil {
+		fields = append(fields, keyUsername)
+	}
+
+	if c.Password != nil {
+		fields = append(fields, keyPassword)
+	}
+
+	if c.Host != "" {
+		fields = append(fields, keyHost)
+	}
+
+	if c.Port != "" {
+		fields = append(fields, keyPort)
+	}
+
+	if c.Database != "" {
+		fields = append(fields, keyDatabase)
+	}
+
+	for key := range c.Properties {
+		fields = append(fields, key)
+	}
+
+	return fields
+}
+
+func (c *connection) Origin(field string) string {
+	if field == keyNumericPort {
+		field = keyPort
+	}
+
+	return c.origins[field]
+}
+
+func (c *connection) Label(source string) *connection {
+	for _, field := range c.fields() {
+		if c.Origin(field) == "" {
+			c.setOrigin(field, source)
+		}
+	}
+
+	return c
+}
+
+func (c *connection) Overlay(layer *connection, source ...string) *connection {
+	merged := c.clone()
+	if layer == nil {
+		return merged
+	}
+
+	label := ""
+	if len(source) > 0 {
+		label = source[0]
+	}
+
+	// a property of the layer replaces every value of the same key, and the
+	// layer's properties keep their order
+	properties := layer.PropertyList()
+	for _, property := range properties {
+		merged.deleteProperty(property.Key)
+	}
+
+	for _, property := range properties {
+		merged.addProperty(property.Key, property.Value, property.Raw)
+	}
+
+	for _, field := range layer.fields() {
+		switch field {
+		case keyType:
+			merged.Type = copyPtr(layer.Type)
+		case keyUsername:
+			merged.Username = copyPtr(layer.Username)
+		case keyPassword:
+			merged.Password = copyPtr(layer.Password)
+		case keyHost:
+			// the hosts of the layer replace the whole list
+			merged.Host = layer.Host
+			merged.Hosts = append([]Endpoint(nil), layer.Hosts...)
+		case keyPort:
+			merged.Port = layer.Port
+			merged.NumericPort = layer.NumericPort
+		case keyDatabase:
+			merged.Database = layer.Database
+			merged.Path = layer.Path
+		}
+
+		if origin := layer.Origin(field); origin != "" {
+			merged.setOrigin(field, origin)
+		} else {
+			merged.setOrigin(field, label)
+		}
+	}
+
+	return merged
+}
+
+func Merge(layers ...*connection) *connection {
+	merged := &connection{}
+	for _, layer := range layers {
+		merged = merged.Overlay(layer)
+	}
+
+	return merged
+}
@@ -0,0 +1,363 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const mongoDefaultPort = "27017"
+const mongoExternal = "$external"
+
+var mongoMechanisms = []string{
+	"SCRAM-SHA-1", "SCRAM-SHA-256", "MONGODB-X509", "GSSAPI", "PLAIN", "MONGODB-AWS", "MONGODB-OIDC",
+}
+
+var mongoReadPreferences = []string{
+	"primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest",
+}
+
+// MongoDBOptions holds the settings of a MongoDB connection string, checked
+// against the connection string and authentication specifications.
+type MongoDBOptions struct {
+	SRV   bool
+	Hosts []Endpoint
+	// Database is the default database, "" when the string names none
+	Database string
+	// AuthSource is the database the credentials are checked against. It is
+	// "" when the string has neither credentials nor an authSource.
+	AuthSource              string
+	AuthMechanism           string
+	AuthMechanismProperties map[string]string
+	ReplicaSet              string
+	ReadPreference          string
+	// ReadPreferenceTags holds the tag sets in order of preference; an empty
+	// set matches any server
+	ReadPreferenceTags []map[string]string
+	TLS                bool
+	// SRVServiceName and SRVMaxHosts are only read from a mongodb+srv string;
+	// SRVMaxHosts is 0 when there is no limit
+	SRVServiceName string
+	SRVMaxHosts    int
+}
+
+// MongoDB reads a mongodb:// or mongodb+srv:// connection. Option names are
+// case-insensitive, and an option given more than once keeps its last value,
+// except readPreferenceTags which keeps them all.
+func (c *connection) MongoDB() (*MongoDBOptions, error) {
+	if !c.isForAny("mongodb", "mongodb+srv") {
+		return nil, fmt.Errorf("%s is not a MongoDB connection", describeType(c))
+	}
+
+	o := &MongoDBOptions{
+		SRV:        c.IsFor("mongodb+srv"),
+		Database:   c.Database,
+		ReplicaSet: c.mongoOption("replicaSet"),
+	}
+
+	if err := o.readHosts(c); err != nil {
+		return nil, err
+	}
+
+	if err := o.readSRV(c); err != nil {
+		return nil, err
+	}
+
+	if err := o.readTLS(c); err != nil {
+		return nil, err
+	}
+
+	if err := o.readReadPreference(c); err != nil {
+		return nil, err
+	}
+
+	if err := o.readAuth(c); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *MongoDBOptions) readHosts(c *connection) error {
+	endpoints := c.Endpoints()
+	if len(endpoints) == 0 {
+		return fmt.Errorf("%s has no host", *c.Type)
+	}
+
+	if o.SRV {
+		// the port and the other hosts come from the SRV records
+		if len(endpoints) > 1 {
+			return fmt.Errorf("mongodb+srv takes one host, not %d", len(endpoints))
+		}
+
+		if endpoints[0].Port != "" {
+			return fmt.Errorf("mongodb+srv host %q must not have a port", endpoints[0].Host)
+		}
+
+		if strings.EqualFold(c.mongoOption("directConnection"), "true") {
+			return errors.New("mongodb+srv does not allow directConnection=true")
+		}
+	} else if len(endpoints) > 1 && strings.EqualFold(c.mongoOption("directConnection"), "true") {
+		return fmt.Errorf("directConnection=true takes one host, not %d", len(endpoints))
+	}
+
+	port := mongoDefaultPort
+	if o.SRV {
+		port = ""
+	}
+
+	var err error
+	o.Hosts, err = withDefaultPort(endpoints, port)
+
+	return err
+}
+
+func (o *MongoDBOptions) readSRV(c *connection) error {
+	if !o.SRV {
+		return noMongoSRVOptions(c)
+	}
+
+	o.SRVServiceName = c.mongoOption("srvServiceName")
+
+	var err error
+	o.SRVMaxHosts, err = mongoSRVMaxHosts(c)
+
+	return err
+}
+
+// noMongoSRVOptions returns an error when a mongodb:// string sets an option
+// that only a mongodb+srv string may have.
+func noMongoSRVOptions(c *connection) error {
+	for _, key := range mongoSRVOptions {
+		if _, ok := c.mongoOptionSet(key); ok {
+			return fmt.Errorf("%s needs mongodb+srv", key)
+		}
+	}
+
+	return nil
+}
+
+// mongoSRVMaxHosts reads srvMaxHosts, 0 when it is not set. A limit cannot go
+// with replicaSet or loadBalanced=true, which need every host of the records.
+func mongoSRVMaxHosts(c *connection) (int, error) {
+	v := c.mongoOption("srvMaxHosts")
+	if v == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("srvMaxHosts %q is not a whole number", v)
+	}
+
+	if n > 0 && c.mongoOption("replicaSet") != "" {
+		return 0, errors.New("srvMaxHosts does not go with replicaSet")
+	}
+
+	if n > 0 && strings.EqualFold(c.mongoOption("loadBalanced"), "true") {
+		return 0, errors.New("srvMaxHosts does not go with loadBalanced=true")
+	}
+
+	return n, nil
+}
+
+func (o *MongoDBOptions) readTLS(c *connection) error {
+	// a mongodb+srv connection uses TLS unless told otherwise
+	o.TLS = o.SRV
+
+	values := map[string]string{}
+	for _, key := range []string{"tls", "ssl"} {
+		value := c.mongoOption(key)
+		if value == "" {
+			continue
+		}
+
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s %q is not true or false", key, value)
+		}
+
+		values[key] = value
+		o.TLS = value == "true"
+	}
+
+	if len(values) == 2 && values["tls"] != values["ssl"] {
+		return fmt.Errorf("tls=%s and ssl=%s disagree", values["tls"], values["ssl"])
+	}
+
+	return nil
+}
+
+func (o *MongoDBOptions) readReadPreference(c *connection) error {
+	if mode := c.mongoOption("readPreference"); mode != "" {
+		if o.ReadPreference = canonicalOf(mode, mongoReadPreferences); o.ReadPreference == "" {
+			return fmt.Errorf("readPreference %q is not one of %s", mode, strings.Join(mongoReadPreferences, ", "))
+		}
+	}
+
+	for _, value := range c.mongoOptions("readPreferenceTags") {
+		tags, err := readMongoPairs("readPreferenceTags", value)
+		if err != nil {
+			return err
+		}
+
+		o.ReadPreferenceTags = append(o.ReadPreferenceTags, tags)
+	}
+
+	if len(o.ReadPreferenceTags) > 0 && (o.ReadPreference == "" || o.ReadPreference == "primary") {
+		return errors.New("readPreferenceTags need a readPreference other than primary")
+	}
+
+	return nil
+}
+
+func (o *MongoDBOptions) readAuth(c *connection) error {
+	if mechanism := c.mongoOption("authMechanism"); mechanism != "" {
+		if o.AuthMechanism = canonicalOf(mechanism, mongoMechanisms); o.AuthMechanism == "" {
+			return fmt.Errorf("authMechanism %q is not one of %s", mechanism, strings.Join(mongoMechanisms, ", "))
+		}
+	}
+
+	if value := c.mongoOption("authMechanismProperties"); value != "" {
+		if o.AuthMechanism == "" {
+			return errors.New("authMechanismProperties need an authMechanism")
+		}
+
+		properties, err := readMongoPairs("authMechanismProperties", value)
+		if err != nil {
+			return err
+		}
+
+		o.AuthMechanismProperties = properties
+	}
+
+	username, hasPassword := stringOf(c.Username), c.Password != nil
+
+	switch o.AuthMechanism {
+	case "SCRAM-SHA-1", "SCRAM-SHA-256", "PLAIN":
+		if username == "" {
+			return fmt.Errorf("%s needs a username", o.AuthMechanism)
+		}
+	case "GSSAPI":
+		if username == "" {
+			return fmt.Errorf("%s needs a username", o.AuthMechanism)
+		}
+
+		if o.AuthMechanismProperties == nil {
+			o.AuthMechanismProperties = map[string]string{}
+		}
+
+		if o.AuthMechanismProperties["SERVICE_NAME"] == "" {
+			o.AuthMechanismProperties["SERVICE_NAME"] = "mongodb"
+		}
+
+		if v, ok := o.AuthMechanismProperties["CANONICALIZE_HOST_NAME"]; ok {
+			if err := oneOf("CANONICALIZE_HOST_NAME", v, "true", "false", "none", "forward", "forwardAndReverse"); err != nil {
+				return err
+			}
+		}
+	case "MONGODB-X509", "MONGODB-OIDC":
+		if hasPassword {
+			return fmt.Errorf("%s takes no password", o.AuthMechanism)
+		}
+	case "MONGODB-AWS":
+		if (username != "") != hasPassword {
+			return fmt.Errorf("%s needs both a username and a password, or neither", o.AuthMechanism)
+		}
+	}
+
+	source, hasSource := c.mongoOptionSet("authSource")
+	if hasSource && source == "" {
+		return errors.New("authSource must not be empty")
+	}
+
+	switch o.AuthMechanism {
+	case "GSSAPI", "MONGODB-X509", "MONGODB-AWS", "MONGODB-OIDC":
+		if hasSource && source != mongoExternal {
+			return fmt.Errorf("%s needs authSource %s, not %q", o.AuthMechanism, mongoExternal, source)
+		}
+
+		o.AuthSource = mongoExternal
+	case "PLAIN":
+		o.AuthSource = firstOf(source, o.Database, mongoExternal)
+	default:
+		// the database of the string is also where the user is defined
+		o.AuthSource = source
+		if c.Username != nil || o.AuthMechanism != "" {
+			o.AuthSource = firstOf(source, o.Database, "admin")
+		}
+	}
+
+	return nil
+}
+
+// mongoOptions returns every value of a MongoDB option, whose name is
+// case-insensitive.
+func (c *connection) mongoOptions(name string) []string {
+	var values []string
+	for _, property := range c.PropertyList() {
+		if strings.EqualFold(property.Key, name) {
+			values = append(values, property.Value)
+		}
+	}
+
+	return values
+}
+
+func (c *connection) mongoOption(name string) string {
+	value, _ := c.mongoOptionSet(name)
+	return value
+}
+
+// mongoOptionSet returns the last value of a MongoDB option, and whether it
+// is set at all.
+func (c *connection) mongoOptionSet(name string) (string, bool) {
+	values := c.mongoOptions(name)
+	if len(values) == 0 {
+		return "", false
+	}
+
+	return values[len(values)-1], true
+}
+
+// readMongoPairs reads "name:value,name:value" into a map. An empty string
+// gives an empty map.
+func readMongoPairs(option string, value string) (map[string]string, error) {
+	pairs := map[string]string{}
+	if value == "" {
+		return pairs, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, v, ok := strings.Cut(pair, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%s %q: %q is not a name:value pair", option, value, pair)
+		}
+
+		pairs[name] = v
+	}
+
+	return pairs, nil
+}
+
+// canonicalOf returns the spelling in allowed that matches value
+// case-insensitively, or "".
+func canonicalOf(value string, allowed []string) string {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return a
+		}
+	}
+
+	return ""
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,299 @@
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const optionFileClientGroup = "client"
+const optionFileMaxDepth = 10
+
+var optionFileTypes = []string{"mysql", "mariadb"}
+
+type optionFileEntry struct {
+	group string
+	key   string
+	value string
+}
+
+func MySQLOptionFile(path string, groups ...string) Resolver {
+	return func(c *connection) error {
+		if !c.isForAny(optionFileTypes...) {
+			return nil
+		}
+
+		path, err := expandHome(path)
+		if err != nil {
+			return err
+		}
+
+		// like the mysql client, a missing option file is not an error
+		if _, err = os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		options, err := readOptionFile(path, groups...)
+		if err != nil {
+			return err
+		}
+
+		origin := "file:" + path
+
+		if v, ok := options["user"]; ok && c.Username == nil {
+			c.Username = &v
+			c.setOrigin(keyUsername, origin)
+		}
+
+		if v, ok := options["password"]; ok && c.Password == nil {
+			c.Password = &v
+			c.setOrigin(keyPassword, origin)
+		}
+
+		if v, ok := options["host"]; ok && c.Host == "" {
+			c.Host = v
+			c.setOrigin(keyHost, origin)
+		}
+
+		if v, ok := options["port"]; ok && c.Port == "" {
+			c.Port = v
+			if numericPort, err := strconv.Atoi(v); err == nil {
+				c.NumericPort = numericPort
+			}
+			c.setOrigin(keyPort, origin)
+		}
+
+		if v, ok := options["database"]; ok && c.Database == "" {
+			c.Database = v
+			c.setOrigin(keyDatabase, origin)
+		}
+
+		if v, ok := options["socket"]; ok && !c.HasProperty("socket") {
+			c.addProperty("socket", v)
+			c.setOrigin("socket", origin)
+		}
+
+		return nil
+	}
+}
+
+// expandHome replaces a leading ~ with the home directory of the user.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, path[1:]), nil
+}
+
+func readOptionFile(path string, groups ...string) (map[string]string, error) {
+	entries, err := readOptionFileEntries(path, make(map[string]bool), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := map[string]bool{optionFileClientGroup: true}
+	for _, group := range groups {
+		selected[strings.ToLower(group)] = true
+	}
+
+	// like the mysql client, every selected group is applied in file order,
+	// so the value read last wins.
+	options := make(map[string]string)
+	for _, entry := range entries {
+		if selected[entry.group] {
+			options[entry.key] = entry.value
+		}
+	}
+
+	return options, nil
+}
+
+func readOptionFileEntries(path string, visited map[string]bool, depth int) ([]optionFileEntry, error) {
+	if depth > optionFileMaxDepth {
+		return nil, fmt.Errorf("option file %s: too many nested includes", path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if visited[abs] {
+		return nil, nil
+	}
+	visited[abs] = true
+
+	f, err := os.Open(abs)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []optionFileEntry
+	group := ""
+	line := 0
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case text == "", text[0] == '#', text[0] == ';':
+			continue
+		case text[0] == '[':
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("option file %s:%d: malformed group %q", abs, line, text)
+			}
+
+			group = strings.ToLower(strings.TrimSpace(text[1 : len(text)-1]))
+		case text[0] == '!':
+			directive, target, _ := strings.Cut(text, " ")
+			target = strings.TrimSpace(target)
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(abs), target)
+			}
+
+			var included []optionFileEntry
+			switch directive {
+			case "!include":
+				included, err = readOptionFileEntries(target, visited, depth+1)
+			case "!includedir":
+				included, err = readOptionFileDir(target, visited, depth+1)
+			default:
+				err = fmt.Errorf("option file %s:%d: unknown directive %q", abs, line, directive)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, included...)
+		default:
+			if group == "" {
+				return nil, fmt.Errorf("option file %s:%d: option outside of a group", abs, line)
+			}
+
+			key, value, _ := strings.Cut(text, "=")
+			key = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+
+			if value, err = unquoteOptionValue(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("option file %s:%d: %w", abs, line, err)
+			}
+
+			entries = append(entries, optionFileEntry{group: group, key: key, value: value})
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func readOptionFileDir(dir string, visited map[string]bool, depth int) ([]optionFileEntry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		if ext := filepath.Ext(file.Name()); !file.IsDir() && (ext == ".cnf" || ext == ".ini") {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var entries []optionFileEntry
+	for _, name := range names {
+		included, err := readOptionFileEntries(filepath.Join(dir, name), visited, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, included...)
+	}
+
+	return entries, nil
+}
+
+func unquoteOptionValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		if i := strings.IndexByte(value, '#'); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+
+		return unescapeOptionValue(value), nil
+	}
+
+	end := -1
+	for i := 1; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+		} else if value[i] == quote {
+			end = i
+			break
+		}
+	}
+
+	if end < 0 {
+		return "", fmt.Errorf("unterminated quote in %q", value)
+	}
+
+	if rest := strings.TrimSpace(value[end+1:]); rest != "" && rest[0] != '#' {
+		return "", fmt.Errorf("unexpected text after quoted value %q", value)
+	}
+
+	return unescapeOptionValue(value[1:end]), nil
+}
+
+func unescapeOptionValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 's':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, path string, content string) string {
+	t.Helper()
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestMySQLOptionFileFillsMissingFields(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, filepath.Join(dir, "my.cnf"), `
+# global settings
+[client]
+user = alice
+password = "s3cr#t"
+port=3307
+socket = /var/run/mysqld/mysqld.sock # trailing comment
+
+[mysqldump]
+user = dumper
+`)
+
+	conn, err := NewParser().Resolver(MySQLOptionFile(path)).Parse("mysql://db.example.com/app")
+
+	assert.NoError(t, err)
+	assertConnection(t, &connection{
+		Type:        toPtr("mysql"),
+		Username:    toPtr("alice"),
+		Password:    toPtr("s3cr#t"),
+		Host:        "db.example.com",
+		Port:        "3307",
+		NumericPort: 3307,
+		Database:    "app",
+		Properties: map[string][]string{
+			"socket": {"/var/run/mysqld/mysqld.sock"},
+		},
+		origins: map[string]string{
+			"username": "file:" + path,
+			"password": "file:" + path,
+			"port":     "file:" + path,
+			"socket":   "file:" + path,
+		},
+	}, conn)
+}
+
+func TestMySQLOptionFileSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, filepath.Join(dir, "my.cnf"), "[client]\nsocket=/var/run/mysqld/mysqld.sock\n")
+	p := NewParser().Resolver(MySQLOptionFile(path))
+
+	conn, err := p.Parse("mysql:///app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/run/mysqld/mysqld.sock", conn.SocketPath())
+	assert.Equal(t, "unix", conn.Network())
+	assert.Equal(t, "/var/run/mysqld/mysqld.sock", conn.Address())
+
+	// a host, as in the mysql client, means TCP
+	conn, err = p.Parse("mysql://db.example.com/app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", conn.SocketPath())
+	assert.Equal(t, "tcp", conn.Network())
+}
+
+func TestMySQLOptionFileKeepsExplicitValues(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, filepath.Join(dir, "my.cnf"), "[client]\nuser=alice\npassword=secret\nhost=localhost\n")
+
+	conn, err := NewParser().Resolver(MySQLOptionFile(path)).Parse("mysql://bob:@db.example.com:3306/app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", *conn.Username)
+	assert.Equal(t, "", *conn.Password)
+	assert.Equal(t, "db.example.com", conn.Host)
+}
+
+func TestMySQLOptionFileCustomGroupsAndIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "conf.d", "20-app.cnf"), "[app]\npassword='it\\'s'\nhost=app.internal\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "10-base.cnf"), "[client]\nhost=base.internal\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "ignored.txt"), "[client]\nhost=ignored\n")
+	writeFile(t, filepath.Join(dir, "extra.cnf"), "[APP]\nuser=\"app\\\\user\"\ndefault-character-set=utf8mb4\n")
+	path := writeFile(t, filepath.Join(dir, "my.cnf"), "[client]\nuser=alice\npassword=secret\n!include extra.cnf\n!includedir "+filepath.Join(dir, "conf.d")+"\n")
+
+	options, err := readOptionFile(path, "app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"user":                  `app\user`,
+		"password":              "it's",
+		"host":                  "app.internal",
+		"default_character_set": "utf8mb4",
+	}, options)
+
+	options, err = readOptionFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", options["user"])
+	assert.Equal(t, "base.internal", options["host"])
+}
+
+func TestMySQLOptionFileMissingOrInHome(t *testing.T) {
+	conn, err := NewParser().Resolver(MySQLOptionFile(filepath.Join(t.TempDir(), "missing.cnf"))).Parse("mysql://example.com/db")
+
+	assert.NoError(t, err)
+	assert.Nil(t, conn.Username)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".my.cnf"), "[client]\nuser=alice\n")
+
+	conn, err = NewParser().Resolver(MySQLOptionFile("~/.my.cnf")).Parse("mysql://example.com/db")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", *conn.Username)
+}
+
+func TestMySQLOptionFileIgnoresOtherTypes(t *testing.T) {
+	conn, err := NewParser().Resolver(MySQLOptionFile("/does/not/exist")).Parse("postgres://example.com/db")
+
+	assert.NoError(t, err)
+	assert.Nil(t, conn.Username)
+}
+
+func TestMySQLOptionFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	checks := map[string]string{
+		"option outside of a group": "user=alice\n",
+		"malformed group":           "[client\n",
+		"unknown directive":         "!import other.cnf\n",
+		"unterminated quote":        "[client]\npassword=\"secret\n",
+		"missing include":           "!include missing.cnf\n",
+	}
+
+	for name, content := range checks {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, filepath.Join(dir, name+".cnf"), content)
+
+			_, err := NewParser().Resolver(MySQLOptionFile(path)).Parse("mysql://example.com/db")
+
+			assert.Error(t, err)
+		})
+	}
+
+	// a path that exists but cannot be read as a file
+	_, err := NewParser().Resolver(MySQLOptionFile(dir)).FromPair("type=mysql host=example.com")
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// [user[:password]@][net[(address)]]/dbname[?param=value]
+func detectMySQLDSN(p *parser, input string) float64 {
+	// mysql://user@tcp(host)/db is a URL, however it is written
+	if urlSchemePattern.MatchString(input) {
+		return 0
+	}
+
+	slash := strings.LastIndex(input, "/")
+	if slash < 0 {
+		return 0
+	}
+
+	head := input[:slash]
+	at := strings.LastIndex(head, "@")
+	address := head[at+1:]
+
+	// "/db" alone is too vague, an address or user info has to be there
+	if at < 0 && address == "" {
+		return 0
+	}
+
+	if address != "" && !isMySQLAddress(address) {
+		return 0
+	}
+
+	return 0.95
+}
+
+func isMySQLAddress(address string) bool {
+	open := strings.Index(address, "(")
+	if open <= 0 || !strings.HasSuffix(address, ")") || strings.Contains(address[open+1:len(address)-1], ")") {
+		return false
+	}
+
+	for _, r := range address[:open] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *parser) fromMySQLDSN(input string) (*connection, error) {
+	slash := strings.LastIndex(input, "/")
+	if slash < 0 {
+		return nil, &SyntaxError{Offset: len(input), Message: "missing \"/\" before the database name"}
+	}
+
+	c := &connection{}
+	c.assign(keyType, "mysql")
+
+	head := input[:slash]
+	if at := strings.LastIndex(head, "@"); at >= 0 {
+		username, password, hasPassword := strings.Cut(head[:at], ":")
+		c.assign(keyUsername, username)
+		if hasPassword {
+			c.assign(keyPassword, password)
+		}
+
+		head = head[at+1:]
+	}
+
+	if head != "" {
+		network, address := head, ""
+		if open := strings.Index(head, "("); open >= 0 {
+			if !strings.HasSuffix(head, ")") {
+				return nil, &SyntaxError{Offset: slash - 1, Message: "unclosed address"}
+			}
+
+			network, address = head[:open], head[open+1:len(head)-1]
+		}
+
+		switch network {
+		case "tcp", "tcp4", "tcp6":
+			if host, port, err := net.SplitHostPort(address); err == nil {
+				c.Host = host
+				c.assign(keyPort, port)
+			} else {
+				c.Host = trimBrackets(address)
+			}
+		case networkUnix:
+			c.Host = address
+			if !isSocketPath(address) {
+				c.addProperty("net", network)
+			}
+		default:
+			c.Host = address
+			c.addProperty("net", network)
+		}
+	}
+
+	database, query, _ := strings.Cut(input[slash+1:], "?")
+	c.Database = database
+
+	if err := c.addQuery(query); err != nil {
+		return nil, fmt.Errorf("mysql dsn: %w", err)
+	}
+
+	return c, nil
+}
+
+func renderMySQLDSN(c *connection) (string, error) {
+	if err := oneHost(c, "mysql dsn", "MySQL DSN"); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	if c.Username != nil || c.Password != nil {
+		b.WriteString(stringOf(c.Username))
+		if c.Password != nil {
+			b.WriteString(":" + *c.Password)
+		}
+
+		b.WriteString("@")
+	}
+
+	if c.Host != "" || c.Port != "" {
+		network, address := c.GetProperty("net", networkTCP), hostPort(c.Host, c.Port)
+		if socket := c.SocketPath(); socket != "" {
+			// unix(path) has no room for a port
+			if c.Port != "" {
+				return "", errors.New("mysql dsn: a unix socket has no port in the MySQL DSN form")
+			}
+
+			network, address = networkUnix, socket
+		} else if network != networkTCP {
+			address = c.Host
+		}
+
+		b.WriteString(network + "(" + address + ")")
+	}
+
+	b.WriteString("/" + c.Database)
+
+	var properties []Property
+	for _, property := range c.PropertyList() {
+		if property.Key != "net" {
+			properties = append(properties, property)
+		}
+	}
+
+	if query := encodeQuery(properties); query != "" {
+		b.WriteString("?" + query)
+	}
+
+	return b.String(), nil
+}
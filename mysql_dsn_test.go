@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mysqlDSNChecks = map[string]dataProvider{
+	"mysql dsn - full": {
+		input: "alice:s3cr@t@tcp(db.example.com:3306)/app?parseTime=true&loc=Local",
+		expected: &connection{
+			Type:        toPtr("mysql"),
+			Username:    toPtr("alice"),
+			Password:    toPtr("s3cr@t"),
+			Host:        "db.example.com",
+			Port:        "3306",
+			NumericPort: 3306,
+			Database:    "app",
+			Properties: map[string][]string{
+				"parseTime": {"true"},
+				"loc":       {"Local"},
+			},
+		},
+	},
+	"mysql dsn - ipv6 address": {
+		input: "root@tcp([::1]:3306)/app",
+		expected: &connection{
+			Type:        toPtr("mysql"),
+			Username:    toPtr("root"),
+			Host:        "::1",
+			Port:        "3306",
+			NumericPort: 3306,
+			Database:    "app",
+		},
+	},
+	"mysql dsn - address without port": {
+		input: "root:@tcp(localhost)/",
+		expected: &connection{
+			Type:     toPtr("mysql"),
+			Username: toPtr("root"),
+			Password: toPtr(""),
+			Host:     "localhost",
+		},
+	},
+	"mysql dsn - unix socket": {
+		input: "alice@unix(/var/run/mysqld/mysqld.sock)/app",
+		expected: &connection{
+			Type:     toPtr("mysql"),
+			Username: toPtr("alice"),
+			Host:     "/var/run/mysqld/mysqld.sock",
+			Database: "app",
+		},
+	},
+	"mysql dsn - other network": {
+		input: "alice@cloudsql(project:region:instance)/app",
+		expected: &connection{
+			Type:     toPtr("mysql"),
+			Username: toPtr("alice"),
+			Host:     "project:region:instance",
+			Database: "app",
+			Properties: map[string][]string{
+				"net": {"cloudsql"},
+			},
+		},
+	},
+	"mysql dsn - no address": {
+		input: "root@/app",
+		expected: &connection{
+			Type:     toPtr("mysql"),
+			Username: toPtr("root"),
+			Database: "app",
+		},
+	},
+	"mysql dsn - invalid query": {
+		input:        "root@/app?x=%zz",
+		expectsError: true,
+	},
+}
+
+func TestMySQLDSN(t *testing.T) {
+	for name, check := range mysqlDSNChecks {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, FormatMySQLDSN, Detect(check.input))
+
+			conn, err := Parse(check.input)
+
+			if check.expectsError {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assertConnection(t, check.expected, conn)
+		})
+	}
+}
+
+func TestMySQLDSNErrors(t *testing.T) {
+	_, err := ParseAs(FormatMySQLDSN, "root@tcp(localhost:3306")
+	assert.EqualError(t, err, `syntax error at offset 23: missing "/" before the database name`)
+
+	_, err = ParseAs(FormatMySQLDSN, "root@tcp(localhost/app")
+	assert.EqualError(t, err, "syntax error at offset 17: unclosed address")
+}
@@ -0,0 +1,237 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+var odbcAliases = map[string]string{
+	"server":   keyHost,
+	"host":     keyHost,
+	"port":     keyPort,
+	"database": keyDatabase,
+	"uid":      keyUsername,
+	"user":     keyUsername,
+	"pwd":      keyPassword,
+	"password": keyPassword,
+}
+
+type odbcAttribute struct {
+	start int
+	key   string
+	value string
+}
+
+func detectODBC(p *parser, input string) float64 {
+	if !strings.Contains(input, "=") {
+		return 0
+	}
+
+	// a driver or a data source name is what sets ODBC apart from ADO.NET
+	for rest := input; rest != ""; {
+		var column string
+		column, rest, _ = strings.Cut(rest, ";")
+
+		key, _, _ := strings.Cut(column, "=")
+		if key = strings.TrimSpace(key); strings.EqualFold(key, "driver") || strings.EqualFold(key, "dsn") {
+			return 0.9
+		}
+	}
+
+	return 0
+}
+
+func (p *parser) fromODBC(input string) (*connection, error) {
+	attributes, err := lexODBC(input)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &connection{}
+	c.assign(keyType, "odbc")
+
+	for _, attribute := range attributes {
+		if _, err = p.assignRaw(c, odbcKey(attribute.key), attribute.key, attribute.value); err != nil {
+			return nil, &SyntaxError{Offset: attribute.start, Message: err.Error()}
+		}
+	}
+
+	splitSqlServerHost(c)
+
+	return c, nil
+}
+
+// lexODBC splits key=value attributes on ";". A value wrapped in braces may
+// hold any character, and "}}" stands for a closing brace.
+func lexODBC(input string) ([]odbcAttribute, error) {
+	var attributes []odbcAttribute
+
+	for at := 0; at < len(input); {
+		end := strings.IndexByte(input[at:], ';')
+		if end < 0 {
+			end = len(input)
+		} else {
+			end += at
+		}
+
+		key, rest, ok := strings.Cut(input[at:end], "=")
+		if !ok {
+			if strings.TrimSpace(key) != "" {
+				return nil, &SyntaxError{Offset: at, Message: fmt.Sprintf("missing \"=\" in %q", strings.TrimSpace(key))}
+			}
+
+			at = end + 1
+			continue
+		}
+
+		attribute := odbcAttribute{start: at, key: strings.TrimSpace(key)}
+		if attribute.key == "" {
+			return nil, &SyntaxError{Offset: at, Message: "attribute has no key"}
+		}
+
+		valueAt := at + len(key) + 1
+		value := strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(value, "{") {
+			attribute.value = strings.TrimSpace(rest)
+			attributes = append(attributes, attribute)
+			at = end + 1
+			continue
+		}
+
+		// the braces may hide a ";", so the end is found again from the opening brace
+		open := valueAt + len(rest) - len(value)
+		braced, after, err := readBraced(input, open)
+		if err != nil {
+			return nil, err
+		}
+
+		end = strings.IndexByte(input[after:], ';')
+		if end < 0 {
+			end = len(input)
+		} else {
+			end += after
+		}
+
+		if trailing := strings.TrimSpace(input[after:end]); trailing != "" {
+			return nil, &SyntaxError{Offset: after, Message: fmt.Sprintf("unexpected %q after the closing brace", trailing)}
+		}
+
+		attribute.value = braced
+		attributes = append(attributes, attribute)
+		at = end + 1
+	}
+
+	return attributes, nil
+}
+
+// readBraced reads the value that opens at input[open] == '{' and returns it
+// with the offset just past its closing brace.
+func readBraced(input string, open int) (string, int, error) {
+	var b strings.Builder
+
+	for at := open + 1; at < len(input); at++ {
+		if input[at] != '}' {
+			b.WriteByte(input[at])
+			continue
+		}
+
+		if at+1 < len(input) && input[at+1] == '}' {
+			b.WriteByte('}')
+			at++
+			continue
+		}
+
+		return b.String(), at + 1, nil
+	}
+
+	return "", 0, &SyntaxError{Offset: open, Message: "unterminated { brace"}
+}
+
+// odbcKey maps an ODBC attribute name, which is case-insensitive, to the key
+// it is stored under.
+func odbcKey(raw string) string {
+	name := strings.ToLower(raw)
+	if alias, ok := odbcAliases[name]; ok {
+		return alias
+	}
+
+	return name
+}
+
+func renderODBC(c *connection) (string, error) {
+	if err := oneHost(c, "odbc", "ODBC"); err != nil {
+		return "", err
+	}
+
+	var pairs []string
+
+	write := func(key string, value string) {
+		pairs = append(pairs, key+"="+quoteODBC(value))
+	}
+
+	properties := c.PropertyList()
+	for _, property := range properties {
+		switch property.Key {
+		case "driver":
+			// driver names are braced by convention, as most hold spaces
+			pairs = append(pairs, property.name()+"={"+strings.ReplaceAll(property.Value, "}", "}}")+"}")
+		case "dsn":
+			write(property.name(), property.Value)
+		}
+	}
+
+	// SQL Server drivers take the port after a comma and ignore PORT
+	sqlServer := c.isForAny("sqlserver", "mssql") || strings.Contains(strings.ToLower(c.GetProperty("driver")), "sql server")
+	sqlServer = sqlServer && c.SocketPath() == ""
+
+	server := c.Host
+	if instance := c.GetProperty("instance"); instance != "" && sqlServer {
+		server += `\` + instance
+	}
+
+	if c.Port != "" && sqlServer {
+		server += "," + c.Port
+	}
+
+	if server != "" {
+		write("SERVER", server)
+	}
+
+	if c.Port != "" && !sqlServer {
+		write("PORT", c.Port)
+	}
+
+	if c.Database != "" {
+		write("DATABASE", c.Database)
+	}
+
+	if c.Username != nil {
+		write("UID", *c.Username)
+	}
+
+	if c.Password != nil {
+		write("PWD", *c.Password)
+	}
+
+	for _, property := range properties {
+		switch property.Key {
+		case "driver", "dsn":
+		case "instance":
+			if !sqlServer {
+				write(property.name(), property.Value)
+			}
+		default:
+			write(property.name(), property.Value)
+		}
+	}
+
+	return strings.Join(pairs, ";"), nil
+}
+
+func quoteODBC(value string) string {
+	if !strings.ContainsAny(value, ";{}") && strings.TrimSpace(value) == value {
+		return value
+	}
+
+	return "{" + strings.ReplaceAll(value, "}", "}}") + "}"
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var odbcChecks = map[string]dataProvider{
+	"odbc - sql server with braced values": {
+		input: "DRIVER={ODBC Driver 18 for SQL Server};SERVER=tcp:db.example.com,1433;DATABASE=app;UID=sa;PWD={p;w}}d};Encrypt=yes",
+		expected: &connection{
+			Type:        toPtr("odbc"),
+			Username:    toPtr("sa"),
+			Password:    toPtr("p;w}d"),
+			Host:        "db.example.com",
+			Port:        "1433",
+			NumericPort: 1433,
+			Database:    "app",
+			Properties: map[string][]string{
+				"driver":  {"ODBC Driver 18 for SQL Server"},
+				"encrypt": {"yes"},
+			},
+		},
+	},
+	"odbc - data source name": {
+		input: "DSN=reporting; Uid = alice ; Pwd={ spaced } ;",
+		expected: &connection{
+			Type:     toPtr("odbc"),
+			Username: toPtr("alice"),
+			Password: toPtr(" spaced "),
+			Properties: map[string][]string{
+				"dsn": {"reporting"},
+			},
+		},
+	},
+	"odbc - separate port and braces inside a value": {
+		input: "Driver={PostgreSQL Unicode};Server=localhost;Port=5432;Database=app;Options={-c search_path={app}}}",
+		expected: &connection{
+			Type:        toPtr("odbc"),
+			Host:        "localhost",
+			Port:        "5432",
+			NumericPort: 5432,
+			Database:    "app",
+			Properties: map[string][]string{
+				"driver":  {"PostgreSQL Unicode"},
+				"options": {"-c search_path={app}"},
+			},
+		},
+	},
+	"odbc - unterminated brace": {
+		input:        "DRIVER={SQL Server;SERVER=db",
+		expectsError: true,
+	},
+	"odbc - text after a brace": {
+		input:        "DRIVER={SQL Server}x;SERVER=db",
+		expectsError: true,
+	},
+	"odbc - attribute without a value": {
+		input:        "DSN=app;readonly",
+		expectsError: true,
+	},
+}
+
+func TestODBC(t *testing.T) {
+	for name, check := range odbcChecks {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, FormatODBC, Detect(check.input))
+
+			conn, err := Parse(check.input)
+
+			if check.expectsError {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assertConnection(t, check.expected, conn)
+		})
+	}
+}
+
+func TestODBCErrors(t *testing.T) {
+	_, err := ParseAs(FormatODBC, "DRIVER={SQL Server;SERVER=db")
+	assert.EqualError(t, err, "syntax error at offset 7: unterminated { brace")
+
+	_, err = ParseAs(FormatODBC, "DRIVER={SQL Server} x;SERVER=db")
+	assert.EqualError(t, err, `syntax error at offset 19: unexpected "x" after the closing brace`)
+
+	_, err = ParseAs(FormatODBC, "=x")
+	assert.EqualError(t, err, "syntax error at offset 0: attribute has no key")
+
+	_, err = NewParser(WithStrict(true)).ParseAs(FormatODBC, "DSN=app;UID=a;User=b")
+	assert.EqualError(t, err, "syntax error at offset 14: username is set more than once")
+}
+
+func TestRenderODBC(t *testing.T) {
+	input := "DRIVER={ODBC Driver 18 for SQL Server};SERVER=db.example.com\\SQLEXPRESS,1433;UID=sa;PWD={p;w}}d};Encrypt=yes"
+	conn, err := Parse(input)
+	assert.NoError(t, err)
+
+	rendered, err := conn.Render(FormatODBC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, input, rendered)
+
+	conn, err = Parse("postgres://alice@db.example.com:5432/app?sslmode=require")
+	assert.NoError(t, err)
+
+	rendered, err = conn.Render(FormatODBC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "SERVER=db.example.com;PORT=5432;DATABASE=app;UID=alice;sslmode=require", rendered)
+}
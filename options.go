@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"sort"
+)
+
+type Option func(p *parser)
+
+func WithDelimiter(delimiter rune) Option {
+	return func(p *parser) {
+		p.delimiters = []string{string(delimiter)}
+	}
+}
+
+func WithDelimiters(delimiters ...string) Option {
+	return func(p *parser) {
+		p.delimiters = append([]string(nil), delimiters...)
+
+		// the longest delimiter has to be tried first, so "; " wins over ";"
+		sort.SliceStable(p.delimiters, func(i, j int) bool {
+			return len(p.delimiters[i]) > len(p.delimiters[j])
+		})
+	}
+}
+
+func WithCollapseSpace(collapse bool) Option {
+	return func(p *parser) {
+		p.collapseSpace = collapse
+	}
+}
+
+func WithKeyValueSeparator(separator string) Option {
+	return func(p *parser) {
+		p.separator = separator
+	}
+}
+
+func WithTrimValues(trim bool) Option {
+	return func(p *parser) {
+		p.trimValues = trim
+	}
+}
+
+func WithQuotes(quotes ...rune) Option {
+	return func(p *parser) {
+		p.quotes = append([]rune(nil), quotes...)
+	}
+}
+
+func WithEscape(escape rune) Option {
+	return func(p *parser) {
+		p.escape = escape
+	}
+}
+
+func WithStrict(strict bool) Option {
+	return func(p *parser) {
+		p.strict = strict
+	}
+}
+
+func WithAlias(key string, aliases ...string) Option {
+	return func(p *parser) {
+		for _, alias := range aliases {
+			p.aliases[alias] = key
+		}
+	}
+}
+
+// WithNormalizeHosts runs NormalizeHost on every host the parser returns.
+func WithNormalizeHosts(normalize bool) Option {
+	return func(p *parser) {
+		p.normalizeHost = normalize
+	}
+}
+
+func WithDefaults(defaults *connection) Option {
+	return func(p *parser) {
+		if defaults == nil {
+			p.defaults = nil
+			return
+		}
+
+		p.defaults = defaults.clone()
+	}
+}
+
+func WithResolvers(resolvers ...Resolver) Option {
+	return func(p *parser) {
+		p.resolvers = append(p.resolvers, resolvers...)
+	}
+}
+
+func (p *parser) clone() *parser {
+	cloned := *p
+	cloned.delimiters = append([]string(nil), p.delimiters...)
+	cloned.quotes = append([]rune(nil), p.quotes...)
+	cloned.resolvers = append([]Resolver(nil), p.resolvers...)
+
+	cloned.aliases = make(map[string]string, len(p.aliases))
+	for alias, key := range p.aliases {
+		cloned.aliases[alias] = key
+	}
+
+	return &cloned
+}
+
+func (p *parser) with(opts ...Option) *parser {
+	cloned := p.clone()
+	for _, opt := range opts {
+		opt(cloned)
+	}
+
+	return cloned
+}
+
+func (p *parser) Delimiter(delimiter rune) *parser {
+	return p.with(WithDelimiter(delimiter))
+}
+
+func (p *parser) Delimiters(delimiters ...string) *parser {
+	return p.with(WithDelimiters(delimiters...))
+}
+
+func (p *parser) CollapseSpace(collapse bool) *parser {
+	return p.with(WithCollapseSpace(collapse))
+}
+
+func (p *parser) KeyValueSeparator(separator string) *parser {
+	return p.with(WithKeyValueSeparator(separator))
+}
+
+func (p *parser) TrimValues(trim bool) *parser {
+	return p.with(WithTrimValues(trim))
+}
+
+func (p *parser) Quotes(quotes ...rune) *parser {
+	return p.with(WithQuotes(quotes...))
+}
+
+func (p *parser) Escape(escape rune) *parser {
+	return p.with(WithEscape(escape))
+}
+
+func (p *parser) Strict(strict bool) *parser {
+	return p.with(WithStrict(strict))
+}
+
+func (p *parser) Alias(key string, aliases ...string) *parser {
+	return p.with(WithAlias(key, aliases...))
+}
+
+func (p *parser) NormalizeHosts(normalize bool) *parser {
+	return p.with(WithNormalizeHosts(normalize))
+}
+
+func (p *parser) Defaults(defaults *connection) *parser {
+	return p.with(WithDefaults(defaults))
+}
+
+func (p *parser) Resolver(resolvers ...Resolver) *parser {
+	return p.with(WithResolvers(resolvers...))
+}
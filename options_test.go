@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewParserWithOptions(t *testing.T) {
+	p := NewParser(
+		WithDelimiters("; "),
+		WithCollapseSpace(true),
+		WithKeyValueSeparator(":"),
+		WithTrimValues(true),
+		WithQuotes('\''),
+		WithEscape('\\'),
+	)
+
+	conn, err := p.FromPair(`host: example.com; password: 'it\'s'; user: alice `)
+
+	assert.NoError(t, err)
+	assertConnection(t, &connection{
+		Username: toPtr("alice"),
+		Password: toPtr("it's"),
+		Host:     "example.com",
+	}, conn)
+}
+
+func TestChainedMethodsReturnCopies(t *testing.T) {
+	base := NewParser()
+	semicolon := base.Delimiter(';')
+	strict := semicolon.Strict(true)
+
+	assert.NotSame(t, base, semicolon)
+	assert.Equal(t, []string{" "}, base.delimiters)
+	assert.Equal(t, []string{";"}, semicolon.delimiters)
+	assert.False(t, semicolon.strict)
+	assert.True(t, strict.strict)
+
+	aliased := base.Alias(keyHost, "server")
+	assert.Equal(t, keyHost, aliased.aliases["server"])
+	assert.NotContains(t, base.aliases, "server")
+
+	resolved := base.Resolver(func(c *connection) error { return nil })
+	assert.Len(t, resolved.resolvers, 1)
+	assert.Len(t, base.resolvers, 0)
+
+	for _, p := range []*parser{
+		base.Delimiters(";"),
+		base.CollapseSpace(true),
+		base.KeyValueSeparator(":"),
+		base.TrimValues(true),
+		base.Quotes('\''),
+		base.Escape('\\'),
+		base.Defaults(&connection{Host: "localhost"}),
+	} {
+		assert.NotSame(t, base, p)
+	}
+
+	assert.Equal(t, NewParser(), base)
+}
+
+func TestSharedParserIsSafeAcrossGoroutines(t *testing.T) {
+	shared := NewParser(WithDefaults(&connection{Port: "5432", NumericPort: 5432}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			p := shared
+			input := "host=example.com"
+			if i%2 == 0 {
+				p = shared.Delimiter(';')
+				input = "host=example.com;user=alice"
+			}
+
+			conn, err := p.Parse(input)
+			assert.NoError(t, err)
+			assert.Equal(t, "example.com", conn.Host)
+			assert.Equal(t, "5432", conn.Port)
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, []string{" "}, shared.delimiters)
+}
+
+func TestWithAlias(t *testing.T) {
+	p := NewParser(WithDelimiter(';'), WithAlias(keyHost, "server", "Server"), WithAlias("sslmode", "ssl_mode"))
+
+	conn, err := p.Parse("Server=example.com;ssl_mode=require;dbname=app")
+
+	assert.NoError(t, err)
+	assertConnection(t, &connection{
+		Host:     "example.com",
+		Database: "app",
+		Properties: map[string][]string{
+			"sslmode": {"require"},
+		},
+	}, conn)
+
+	conn, err = p.FromEnv("DB", map[string]string{"DB_SERVER": "env.example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "env.example.com", conn.Host)
+}
+
+func TestWithDefaults(t *testing.T) {
+	defaults := (&connection{Type: toPtr("postgres"), Port: "5432", NumericPort: 5432, Host: "localhost"}).Label("defaults")
+	p := NewParser(WithDefaults(defaults))
+
+	// changing the defaults afterwards does not leak into the parser
+	defaults.Host = "changed"
+
+	conn, err := p.Parse("host=example.com dbname=app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres", *conn.Type)
+	assert.Equal(t, "example.com", conn.Host)
+	assert.Equal(t, "5432", conn.Port)
+	assert.Equal(t, "app", conn.Database)
+	assert.Equal(t, "defaults", conn.Origin("port"))
+	assert.Equal(t, "", conn.Origin("host"))
+
+	conn, err = p.Parse("")
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", conn.Host)
+
+	conn, err = p.Defaults(nil).Parse("")
+	assert.NoError(t, err)
+	assertConnection(t, &connection{}, conn)
+}
+
+func TestWithResolvers(t *testing.T) {
+	var calls []string
+	first := func(c *connection) error {
+		calls = append(calls, "first:"+c.Host)
+		return nil
+	}
+	second := func(c *connection) error {
+		calls = append(calls, "second")
+		return errors.New("boom")
+	}
+
+	_, err := NewParser(WithResolvers(first), WithResolvers(second)).Parse("host=example.com")
+
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, []string{"first:example.com", "second"}, calls)
+}
+
+func TestStrict(t *testing.T) {
+	p := NewParser(WithStrict(true))
+
+	conn, err := p.Parse("host=example.com port=5432 tag=a tag=b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, conn.GetProperties("tag"))
+
+	checks := map[string]struct {
+		input   string
+		message string
+	}{
+		"missing separator":  {input: "host=example.com flag", message: `syntax error at offset 17: missing "=" in "flag"`},
+		"non-numeric port":   {input: "host=example.com port=abc", message: `syntax error at offset 17: port "abc" is not a number`},
+		"duplicate field":    {input: "user=alice username=bob", message: "syntax error at offset 11: username is set more than once"},
+		"duplicate by alias": {input: "db=a dbname=b", message: "syntax error at offset 5: database is set more than once"},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			_, err := p.Parse(check.input)
+
+			assert.EqualError(t, err, check.message)
+		})
+	}
+
+	_, err = p.FromEnv("DB", map[string]string{"DB_PORT": "x"})
+	assert.EqualError(t, err, `DB_PORT: port "x" is not a number`)
+
+	_, err = p.FromVcapServices(`{"x": [{"name": "bad", "credentials": {"port": "x"}}]}`)
+	assert.EqualError(t, err, `service "bad": port: port "x" is not a number`)
+}
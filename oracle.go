@@ -0,0 +1,569 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// the descriptor sections that only group other parameters
+var tnsSections = map[string]bool{
+	"description_list": true,
+	"description":      true,
+	"address_list":     true,
+	"connect_data":     true,
+	"security":         true,
+}
+
+// the properties that belong in CONNECT_DATA and SECURITY when rendering
+var tnsConnectData = []string{"sid", "server", "instance_name", "global_name", "pool_connection_class", "pool_purity", "failover_mode"}
+var tnsSecurity = []string{"ssl_server_cert_dn", "ssl_server_dn_match", "wallet_location", "my_wallet_directory"}
+
+type tnsNode struct {
+	offset   int
+	name     string
+	value    string
+	children []*tnsNode
+}
+
+type tnsReader struct {
+	input  string
+	at     int
+	offset int
+}
+
+func detectTNS(p *parser, input string) float64 {
+	_, target, _ := cutOracleCredentials(input)
+	if target = strings.TrimSpace(target); len(target) >= len("(description") && strings.EqualFold(target[:len("(description")], "(description") {
+		return 0.95
+	}
+
+	return 0
+}
+
+// detectEZConnect looks for [user/password@][//]host[:port][/service[:server][/instance]].
+func detectEZConnect(p *parser, input string) float64 {
+	target, _, _ := strings.Cut(input, "?")
+	if target == "" || strings.ContainsAny(target, " \t=()") || urlSchemePattern.MatchString(target) {
+		return 0
+	}
+
+	credentials, address, offset := cutOracleCredentials(target)
+	if offset > 0 && strings.Contains(credentials, "/") {
+		return 0.6
+	}
+
+	// host:port/name alone is just as likely another database, so only a
+	// server type marks it as Oracle
+	address = strings.TrimPrefix(address, "//")
+	_, path, _ := strings.Cut(address, "/")
+	service, _, _ := strings.Cut(path, "/")
+	if _, server, ok := strings.Cut(service, ":"); ok && isOracleServerType(server) {
+		return 0.85
+	}
+
+	return 0
+}
+
+func isOracleServerType(server string) bool {
+	switch strings.ToLower(server) {
+	case "dedicated", "shared", "pooled":
+		return true
+	}
+
+	return false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+// cutOracleCredentials splits "user/password@target". The offset is where the
+// target starts, zero without credentials. A quoted password may hold "@",
+// and an "@" inside a descriptor does not count.
+func cutOracleCredentials(input string) (string, string, int) {
+	quoted := false
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '"':
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				return "", input, 0
+			}
+		case '@':
+			if !quoted {
+				return input[:i], input[i+1:], i + 1
+			}
+		}
+	}
+
+	return "", input, 0
+}
+
+func (p *parser) oracleCredentials(c *connection, input string) (string, int) {
+	credentials, target, offset := cutOracleCredentials(input)
+	if offset == 0 {
+		return input, 0
+	}
+
+	username, password, hasPassword := strings.Cut(credentials, "/")
+	if username = strings.Trim(username, `"`); username != "" {
+		c.assign(keyUsername, username)
+	}
+
+	if password = strings.Trim(password, `"`); hasPassword && password != "" {
+		c.assign(keyPassword, password)
+	}
+
+	return target, offset
+}
+
+func (p *parser) fromEZConnect(input string) (*connection, error) {
+	c := &connection{}
+	c.assign(keyType, "oracle")
+
+	target, offset := p.oracleCredentials(c, input)
+	if strings.HasPrefix(strings.TrimSpace(target), "(") {
+		return c, applyTNSDescriptor(c, target, offset)
+	}
+
+	// EZConnect Plus names the protocol like a URL scheme
+	if protocol, rest, ok := strings.Cut(target, "://"); ok && !strings.ContainsAny(protocol, "/:@") {
+		c.addProperty("protocol", protocol)
+		target = rest
+		offset += len(protocol) + len("://")
+	} else if strings.HasPrefix(target, "//") {
+		target = target[2:]
+		offset += 2
+	}
+
+	target, query, hasQuery := strings.Cut(target, "?")
+	addresses, path, _ := strings.Cut(target, "/")
+
+	endpoints, err := splitOracleAddresses(addresses, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setEndpoints(endpoints)
+
+	service, instance, hasInstance := strings.Cut(path, "/")
+	service, server, hasServer := strings.Cut(service, ":")
+	c.Database = service
+
+	if hasServer {
+		c.addProperty("server", server)
+	}
+
+	if hasInstance {
+		c.addProperty("instance_name", instance)
+	}
+
+	if hasQuery {
+		if err = c.addQuery(query); err != nil {
+			return nil, &SyntaxError{Offset: offset + len(target) + 1, Message: err.Error()}
+		}
+	}
+
+	return c, nil
+}
+
+// splitOracleAddresses reads "host1,host2:port;host3:port". A host without a
+// port takes the port of the next host in its list.
+func splitOracleAddresses(input string, offset int) ([]Endpoint, error) {
+	var endpoints []Endpoint
+
+	for _, list := range strings.Split(input, ";") {
+		var group []Endpoint
+
+		for _, address := range strings.Split(list, ",") {
+			host, port, _ := cutOraclePort(address)
+			endpoint := Endpoint{Host: strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"), Port: port}
+			if endpoint.Port != "" && !isDigits(endpoint.Port) {
+				return nil, &SyntaxError{Offset: offset, Message: fmt.Sprintf("port %q is not a number", endpoint.Port)}
+			}
+
+			offset += len(address) + 1
+			if endpoint.Host != "" {
+				group = append(group, endpoint)
+			}
+		}
+
+		port := ""
+		for i := len(group) - 1; i >= 0; i-- {
+			if group[i].Port == "" {
+				group[i].Port = port
+			} else {
+				port = group[i].Port
+			}
+		}
+
+		endpoints = append(endpoints, group...)
+	}
+
+	return endpoints, nil
+}
+
+// cutOraclePort splits host:port at the last colon, unless the address ends
+// with the bracket of an IPv6 host.
+func cutOraclePort(address string) (string, string, bool) {
+	if i := strings.LastIndex(address, ":"); i >= 0 && !strings.HasSuffix(address, "]") {
+		return address[:i], address[i+1:], true
+	}
+
+	return address, "", false
+}
+
+func (p *parser) fromTNS(input string) (*connection, error) {
+	c := &connection{}
+	c.assign(keyType, "oracle")
+
+	target, offset := p.oracleCredentials(c, input)
+
+	return c, applyTNSDescriptor(c, target, offset)
+}
+
+// applyTNSDescriptor reads a descriptor such as
+// (DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=svc))).
+func applyTNSDescriptor(c *connection, input string, offset int) error {
+	root, err := parseTNSDescriptor(input, offset)
+	if err != nil {
+		return err
+	}
+
+	var endpoints []Endpoint
+
+	var walk func(node *tnsNode, prefix string)
+	walk = func(node *tnsNode, prefix string) {
+		name := strings.ToLower(node.name)
+
+		switch {
+		case prefix == "" && name == "address":
+			endpoints = append(endpoints, tnsAddress(c, node, len(endpoints) == 0))
+			return
+		case prefix == "" && tnsSections[name]:
+			for _, child := range node.children {
+				walk(child, "")
+			}
+			return
+		case prefix == "" && name == "service_name":
+			c.Database = node.value
+			return
+		}
+
+		if len(node.children) > 0 {
+			for _, child := range node.children {
+				walk(child, prefix+node.name+".")
+			}
+			return
+		}
+
+		c.addProperty(strings.ToLower(prefix+node.name), node.value, prefix+node.name)
+	}
+
+	// a SID is kept in the sid property, as it is not a service name
+	walk(root, "")
+
+	c.setEndpoints(endpoints)
+
+	return nil
+}
+
+// tnsAddress reads one ADDRESS entry. The protocol and other parameters are
+// only kept from the first entry, as the addresses of a descriptor nearly
+// always share them.
+func tnsAddress(c *connection, node *tnsNode, first bool) Endpoint {
+	var endpoint Endpoint
+
+	for _, child := range node.children {
+		switch name := strings.ToLower(child.name); name {
+		case keyHost:
+			endpoint.Host = child.value
+		case keyPort:
+			endpoint.Port = child.value
+		default:
+			if first {
+				c.addProperty(name, child.value, child.name)
+			}
+		}
+	}
+
+	return endpoint
+}
+
+func parseTNSDescriptor(input string, offset int) (*tnsNode, error) {
+	r := &tnsReader{input: input, offset: offset}
+
+	r.space()
+	root, err := r.node()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.space(); r.at < len(r.input) {
+		return nil, r.fail(r.at, fmt.Sprintf("unexpected %q after the descriptor", r.input[r.at:]))
+	}
+
+	return root, nil
+}
+
+func (r *tnsReader) fail(at int, message string) error {
+	return &SyntaxError{Offset: r.offset + at, Message: message}
+}
+
+func (r *tnsReader) space() {
+	for r.at < len(r.input) && strings.IndexByte(" \t\r\n", r.input[r.at]) >= 0 {
+		r.at++
+	}
+}
+
+func (r *tnsReader) node() (*tnsNode, error) {
+	start := r.at
+	if r.at >= len(r.input) || r.input[r.at] != '(' {
+		return nil, r.fail(r.at, `expected "("`)
+	}
+
+	r.at++
+	end := strings.IndexAny(r.input[r.at:], "=()")
+	if end < 0 || r.input[r.at+end] != '=' {
+		return nil, r.fail(start, `expected "=" after the parameter name`)
+	}
+
+	node := &tnsNode{offset: r.offset + start, name: strings.TrimSpace(r.input[r.at : r.at+end])}
+	if node.name == "" {
+		return nil, r.fail(start, "parameter has no name")
+	}
+
+	r.at += end + 1
+	r.space()
+
+	if r.at < len(r.input) && r.input[r.at] == '(' {
+		for r.at < len(r.input) && r.input[r.at] == '(' {
+			child, err := r.node()
+			if err != nil {
+				return nil, err
+			}
+
+			node.children = append(node.children, child)
+			r.space()
+		}
+	} else if value, err := r.value(); err != nil {
+		return nil, err
+	} else {
+		node.value = value
+	}
+
+	if r.at >= len(r.input) || r.input[r.at] != ')' {
+		return nil, r.fail(start, fmt.Sprintf("unclosed %q", "("+node.name))
+	}
+
+	r.at++
+
+	return node, nil
+}
+
+func (r *tnsReader) value() (string, error) {
+	if r.at < len(r.input) && r.input[r.at] == '"' {
+		end := strings.IndexByte(r.input[r.at+1:], '"')
+		if end < 0 {
+			return "", r.fail(r.at, `unterminated " quote`)
+		}
+
+		value := r.input[r.at+1 : r.at+1+end]
+		r.at += end + 2
+		r.space()
+
+		return value, nil
+	}
+
+	start := r.at
+	for r.at < len(r.input) && r.input[r.at] != ')' {
+		if r.input[r.at] == '(' {
+			return "", r.fail(r.at, `unexpected "(" in a value`)
+		}
+
+		r.at++
+	}
+
+	return strings.TrimSpace(r.input[start:r.at]), nil
+}
+
+// errOracleSocket is returned for a Unix domain socket: Oracle reaches a local
+// listener through the IPC protocol, which names a key rather than a path.
+var errOracleSocket = errors.New("oracle: a unix socket has no Oracle form")
+var errEZConnectSID = errors.New("ezconnect: a SID has no EZConnect form, only a service name")
+
+func renderEZConnect(c *connection) (string, error) {
+	if c.SocketPath() != "" {
+		return "", errOracleSocket
+	}
+
+	if c.HasProperty("sid") {
+		return "", errEZConnectSID
+	}
+
+	var b strings.Builder
+	writeOracleCredentials(&b, c)
+
+	if protocol := c.GetProperty("protocol"); protocol != "" && !strings.EqualFold(protocol, "tcp") {
+		b.WriteString(protocol + "://")
+	} else {
+		b.WriteString("//")
+	}
+
+	var addresses []string
+	for _, endpoint := range c.Endpoints() {
+		addresses = append(addresses, hostPort(endpoint.Host, endpoint.Port))
+	}
+
+	b.WriteString(strings.Join(addresses, ",") + "/" + c.Database)
+
+	if server := c.GetProperty("server"); server != "" {
+		b.WriteString(":" + server)
+	}
+
+	if instance := c.GetProperty("instance_name"); instance != "" {
+		b.WriteString("/" + instance)
+	}
+
+	var query []Property
+	for _, property := range c.PropertyList() {
+		switch property.Key {
+		case "protocol", "server", "instance_name":
+		default:
+			query = append(query, property)
+		}
+	}
+
+	if len(query) > 0 {
+		b.WriteString("?" + encodeQuery(query))
+	}
+
+	return b.String(), nil
+}
+
+func renderTNS(c *connection) (string, error) {
+	if c.SocketPath() != "" {
+		return "", errOracleSocket
+	}
+
+	var b strings.Builder
+	writeOracleCredentials(&b, c)
+
+	var description, connectData, security []Property
+	for _, property := range c.PropertyList() {
+		section, _, _ := strings.Cut(property.Key, ".")
+		switch {
+		case property.Key == "protocol":
+		case containsString(tnsConnectData, section):
+			connectData = append(connectData, property)
+		case containsString(tnsSecurity, section):
+			security = append(security, property)
+		default:
+			description = append(description, property)
+		}
+	}
+
+	protocol := c.GetProperty("protocol", "TCP")
+
+	b.WriteString("(DESCRIPTION=")
+	writeTNSParameters(&b, description)
+
+	for _, endpoint := range c.Endpoints() {
+		b.WriteString("(ADDRESS=(PROTOCOL=" + protocol + ")(HOST=" + quoteTNS(endpoint.Host) + ")")
+		if endpoint.Port != "" {
+			b.WriteString("(PORT=" + endpoint.Port + ")")
+		}
+
+		b.WriteString(")")
+	}
+
+	b.WriteString("(CONNECT_DATA=")
+	if c.Database != "" {
+		b.WriteString("(SERVICE_NAME=" + quoteTNS(c.Database) + ")")
+	}
+
+	writeTNSParameters(&b, connectData)
+	b.WriteString(")")
+
+	if len(security) > 0 {
+		b.WriteString("(SECURITY=")
+		writeTNSParameters(&b, security)
+		b.WriteString(")")
+	}
+
+	b.WriteString(")")
+
+	return b.String(), nil
+}
+
+func writeOracleCredentials(b *strings.Builder, c *connection) {
+	if c.Username == nil && c.Password == nil {
+		return
+	}
+
+	b.WriteString(quoteOracleCredential(stringOf(c.Username)))
+	if c.Password != nil {
+		b.WriteString("/" + quoteOracleCredential(*c.Password))
+	}
+
+	b.WriteString("@")
+}
+
+func quoteOracleCredential(value string) string {
+	if strings.ContainsAny(value, `/@(" `) {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+// writeTNSParameters writes flat parameters, and nests "parent.child" keys
+// under their parent.
+func writeTNSParameters(b *strings.Builder, properties []Property) {
+	for i := 0; i < len(properties); {
+		parent, _, nested := strings.Cut(properties[i].name(), ".")
+		if !nested {
+			b.WriteString("(" + strings.ToUpper(parent) + "=" + quoteTNS(properties[i].Value) + ")")
+			i++
+			continue
+		}
+
+		b.WriteString("(" + strings.ToUpper(parent) + "=")
+		for ; i < len(properties); i++ {
+			name, child, ok := strings.Cut(properties[i].name(), ".")
+			if !ok || !strings.EqualFold(name, parent) {
+				break
+			}
+
+			b.WriteString("(" + strings.ToUpper(child) + "=" + quoteTNS(properties[i].Value) + ")")
+		}
+
+		b.WriteString(")")
+	}
+}
+
+func quoteTNS(value string) string {
+	if strings.ContainsAny(value, "()=") || strings.TrimSpace(value) != value {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,246 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var oracleChecks = map[string]struct {
+	format   Format
+	input    string
+	expected *connection
+}{
+	"ezconnect - credentials and service": {
+		format: FormatEZConnect,
+		input:  "scott/tiger@db.example.com:1521/orclpdb",
+		expected: &connection{
+			Type:        toPtr("oracle"),
+			Username:    toPtr("scott"),
+			Password:    toPtr("tiger"),
+			Host:        "db.example.com",
+			Port:        "1521",
+			NumericPort: 1521,
+			Database:    "orclpdb",
+		},
+	},
+	"ezconnect - server type and instance": {
+		format: FormatEZConnect,
+		input:  "//db.example.com/sales:dedicated/inst1",
+		expected: &connection{
+			Type:     toPtr("oracle"),
+			Host:     "db.example.com",
+			Database: "sales",
+			Properties: map[string][]string{
+				"server":        {"dedicated"},
+				"instance_name": {"inst1"},
+			},
+		},
+	},
+	"ezconnect - host, port and service": {
+		format: FormatEZConnect,
+		input:  "scott/tiger@db.example.com:1521/orcl",
+		expected: &connection{
+			Type:        toPtr("oracle"),
+			Username:    toPtr("scott"),
+			Password:    toPtr("tiger"),
+			Host:        "db.example.com",
+			Port:        "1521",
+			NumericPort: 1521,
+			Database:    "orcl",
+		},
+	},
+	"ezconnect plus - protocol, address lists and parameters": {
+		format: FormatEZConnect,
+		input:  `scott/"p@ss/w"@tcps://h1,[::1]:2484;h3:1522/svc?connect_timeout=5`,
+		expected: &connection{
+			Type:        toPtr("oracle"),
+			Username:    toPtr("scott"),
+			Password:    toPtr("p@ss/w"),
+			Host:        "h1",
+			Port:        "2484",
+			NumericPort: 2484,
+			Hosts: []Endpoint{
+				{Host: "h1", Port: "2484"},
+				{Host: "::1", Port: "2484"},
+				{Host: "h3", Port: "1522"},
+			},
+			Database: "svc",
+			Properties: map[string][]string{
+				"protocol":        {"tcps"},
+				"connect_timeout": {"5"},
+			},
+		},
+	},
+	"tns - descriptor with two addresses": {
+		format: FormatTNS,
+		input: `(DESCRIPTION=(CONNECT_TIMEOUT=5)
+			(ADDRESS_LIST=(LOAD_BALANCE=on)
+				(ADDRESS=(PROTOCOL=TCP)(HOST=h1)(PORT=1521))
+				(ADDRESS=(PROTOCOL=TCP)(HOST=h2)(PORT=1522)))
+			(CONNECT_DATA=(SERVICE_NAME=svc)(SERVER=DEDICATED)(FAILOVER_MODE=(TYPE=select)(METHOD=basic)))
+			(SECURITY=(SSL_SERVER_CERT_DN="CN=db,O=example")))`,
+		expected: &connection{
+			Type:        toPtr("oracle"),
+			Host:        "h1",
+			Port:        "1521",
+			NumericPort: 1521,
+			Hosts: []Endpoint{
+				{Host: "h1", Port: "1521"},
+				{Host: "h2", Port: "1522"},
+			},
+			Database: "svc",
+			Properties: map[string][]string{
+				"connect_timeout":      {"5"},
+				"load_balance":         {"on"},
+				"protocol":             {"TCP"},
+				"server":               {"DEDICATED"},
+				"failover_mode.type":   {"select"},
+				"failover_mode.method": {"basic"},
+				"ssl_server_cert_dn":   {"CN=db,O=example"},
+			},
+		},
+	},
+	"tns - credentials and sid": {
+		format: FormatTNS,
+		input:  "scott/tiger@(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SID=orcl)))",
+		expected: &connection{
+			Type:        toPtr("oracle"),
+			Username:    toPtr("scott"),
+			Password:    toPtr("tiger"),
+			Host:        "db",
+			Port:        "1521",
+			NumericPort: 1521,
+			Properties: map[string][]string{
+				"protocol": {"TCP"},
+				"sid":      {"orcl"},
+			},
+		},
+	},
+	"jdbc - oracle descriptor": {
+		format: FormatJDBC,
+		input:  "jdbc:oracle:thin:@(DESCRIPTION=(ADDRESS=(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=svc)))",
+		expected: &connection{
+			Type:        toPtr("oracle"),
+			Host:        "db",
+			Port:        "1521",
+			NumericPort: 1521,
+			Database:    "svc",
+		},
+	},
+}
+
+func TestOracle(t *testing.T) {
+	for name, check := range oracleChecks {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, check.format, Detect(check.input))
+
+			conn, err := Parse(check.input)
+
+			assert.NoError(t, err)
+			assertConnection(t, check.expected, conn)
+		})
+	}
+}
+
+func TestEZConnectNeedsAMarker(t *testing.T) {
+	// without credentials or a server type, host:port/name is not taken for Oracle
+	assert.Equal(t, FormatPairs, Detect("localhost:5432/db"))
+
+	conn, err := ParseAs(FormatEZConnect, "localhost:5432/db")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "oracle", stringOf(conn.Type))
+	assert.Equal(t, "db", conn.Database)
+}
+
+func TestOracleErrors(t *testing.T) {
+	checks := map[string]struct {
+		format   Format
+		input    string
+		expected string
+	}{
+		"bad port":            {FormatEZConnect, "db:15x/orcl", `syntax error at offset 0: port "15x" is not a number`},
+		"bad port in a list":  {FormatEZConnect, "scott@a:1,b:x/orcl", `syntax error at offset 10: port "x" is not a number`},
+		"unclosed":            {FormatTNS, "(DESCRIPTION=(ADDRESS=(HOST=db)", `syntax error at offset 13: unclosed "(ADDRESS"`},
+		"missing equals":      {FormatTNS, "u/p@(DESCRIPTION(HOST=db))", `syntax error at offset 4: expected "=" after the parameter name`},
+		"nameless parameter":  {FormatTNS, "(=db)", "syntax error at offset 0: parameter has no name"},
+		"text after the end":  {FormatTNS, "(HOST=db) x", `syntax error at offset 10: unexpected "x" after the descriptor`},
+		"paren in a value":    {FormatTNS, "(HOST=d(b))", `syntax error at offset 7: unexpected "(" in a value`},
+		"unterminated quote":  {FormatTNS, `(HOST="db)`, `syntax error at offset 6: unterminated " quote`},
+		"not a descriptor":    {FormatTNS, "db:1521/orcl", `syntax error at offset 0: expected "("`},
+		"bad jdbc descriptor": {FormatJDBC, "jdbc:oracle:thin:@(HOST=db", `syntax error at offset 18: unclosed "(HOST"`},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseAs(check.format, check.input)
+
+			assert.EqualError(t, err, check.expected)
+		})
+	}
+}
+
+func TestRenderOracleFormats(t *testing.T) {
+	conn, err := Parse(`scott/"p@ss"@tcps://h1:2484,h2:2485/svc:dedicated?connect_timeout=5&ssl_server_cert_dn=CN%3Ddb`)
+	assert.NoError(t, err)
+
+	rendered, err := conn.Render(FormatEZConnect)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `scott/"p@ss"@tcps://h1:2484,h2:2485/svc:dedicated?connect_timeout=5&ssl_server_cert_dn=CN%3Ddb`, rendered)
+
+	rendered, err = conn.Render(FormatTNS)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `scott/"p@ss"@(DESCRIPTION=(CONNECT_TIMEOUT=5)`+
+		`(ADDRESS=(PROTOCOL=tcps)(HOST=h1)(PORT=2484))(ADDRESS=(PROTOCOL=tcps)(HOST=h2)(PORT=2485))`+
+		`(CONNECT_DATA=(SERVICE_NAME=svc)(SERVER=dedicated))(SECURITY=(SSL_SERVER_CERT_DN="CN=db")))`, rendered)
+
+	parsed, err := ParseAs(FormatTNS, rendered)
+
+	assert.NoError(t, err)
+	assertConnection(t, conn, parsed)
+
+	conn, err = ParseAs(FormatTNS, "(DESCRIPTION=(ADDRESS=(HOST=db))(CONNECT_DATA=(SERVICE_NAME=svc)(FAILOVER_MODE=(TYPE=select)(METHOD=basic))))")
+	assert.NoError(t, err)
+
+	rendered, err = conn.Render(FormatTNS)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db))(CONNECT_DATA=(SERVICE_NAME=svc)(FAILOVER_MODE=(TYPE=select)(METHOD=basic))))", rendered)
+}
+
+func TestRenderOracleSID(t *testing.T) {
+	conn, err := Parse("(DESCRIPTION=(ADDRESS=(HOST=db)(PORT=1521))(CONNECT_DATA=(SID=orcl)))")
+	assert.NoError(t, err)
+
+	rendered, err := conn.Render(FormatTNS)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SID=orcl)))", rendered)
+
+	rendered, err = conn.Render(FormatJDBC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jdbc:oracle:thin:@db:1521:orcl", rendered)
+
+	_, err = conn.Render(FormatEZConnect)
+	assert.EqualError(t, err, "ezconnect: a SID has no EZConnect form, only a service name")
+}
+
+func TestEndpoints(t *testing.T) {
+	assert.Nil(t, (&connection{}).Endpoints())
+	assert.Equal(t, []Endpoint{{Host: "db", Port: "1521"}}, (&connection{Host: "db", Port: "1521"}).Endpoints())
+
+	conn, err := ParseAs(FormatEZConnect, "a,b:1521/svc")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Endpoint{{Host: "a", Port: "1521"}, {Host: "b", Port: "1521"}}, conn.Endpoints())
+
+	// a layer with one host replaces the list of the base
+	merged := conn.Overlay(&connection{Host: "c"})
+
+	assert.Equal(t, []Endpoint{{Host: "c", Port: "1521"}}, merged.Endpoints())
+	assert.Len(t, conn.Endpoints(), 2)
+}
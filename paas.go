@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const urlVariableSuffix = "_URL"
+
+var vcapCredentialAliases = map[string]string{
+	"url":      bindingKeyUri,
+	"hostname": keyHost,
+	"name":     keyDatabase,
+}
+
+type vcapService struct {
+	Name        string                 `json:"name"`
+	Label       string                 `json:"label"`
+	Tags        []string               `json:"tags"`
+	Credentials map[string]interface{} `json:"credentials"`
+}
+
+func (s vcapService) matches(selectors []string) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+
+	for _, selector := range selectors {
+		if selector == s.Name || selector == s.Label {
+			return true
+		}
+
+		for _, tag := range s.Tags {
+			if selector == tag {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (s vcapService) fields() map[string]string {
+	fields := make(map[string]string)
+	for key, value := range s.Credentials {
+		if alias, ok := vcapCredentialAliases[key]; ok {
+			if _, exists := s.Credentials[alias]; exists {
+				continue
+			}
+
+			key = alias
+		}
+
+		switch v := value.(type) {
+		case string:
+			fields[key] = v
+		case float64:
+			fields[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			fields[key] = strconv.FormatBool(v)
+		}
+	}
+
+	return fields
+}
+
+func (p *parser) FromVcapServices(blob string, selectors ...string) (map[string]*connection, error) {
+	var services map[string][]vcapService
+	if err := json.Unmarshal([]byte(blob), &services); err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	for label := range services {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	connections := make(map[string]*connection)
+	for _, label := range labels {
+		for _, service := range services[label] {
+			if service.Label == "" {
+				service.Label = label
+			}
+
+			if !service.matches(selectors) {
+				continue
+			}
+
+			c, err := p.resolve(p.fromFields(service.fields(), nil))
+			if err != nil {
+				return nil, fmt.Errorf("service %q: %w", service.Name, err)
+			}
+
+			if _, ok := connections[service.Name]; ok {
+				return nil, fmt.Errorf("service %q is bound more than once", service.Name)
+			}
+
+			connections[service.Name] = c
+		}
+	}
+
+	return connections, nil
+}
+
+func (p *parser) FromUrlVariables(environ ...map[string]string) (map[string]*connection, error) {
+	env := environment(environ)
+
+	names := make([]string, 0, len(env))
+	for name, value := range env {
+		if len(name) <= len(urlVariableSuffix) || !strings.HasSuffix(name, urlVariableSuffix) {
+			continue
+		}
+
+		if !strings.Contains(value, "://") {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	// a broken value is skipped so that it does not hide the others
+	connections := make(map[string]*connection)
+	var failures []string
+	for _, name := range names {
+		c, err := p.FromUrl(env[name])
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("variable %s: %s", name, err))
+			continue
+		}
+
+		connections[strings.ToLower(strings.TrimSuffix(name, urlVariableSuffix))] = c
+	}
+
+	if len(failures) > 0 {
+		return connections, errors.New(strings.Join(failures, "; "))
+	}
+
+	return connections, nil
+}
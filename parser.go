@@ -1,12 +1,12 @@
 package parser
 
 import (
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 const keyType = "type"
@@ -16,9 +16,17 @@ const keyHost = "host"
 const keyPort = "port"
 const keyNumericPort = "numeric_port"
 const keyDatabase = "database"
-const keyProperties = "properties"
 
 var defaultDelimiter = ' '
+var defaultSeparator = "="
+
+var defaultAliases = map[string]string{
+	"scheme": keyType,
+	"user":   keyUsername,
+	"pass":   keyPassword,
+	"dbname": keyDatabase,
+	"db":     keyDatabase,
+}
 
 type connection struct {
 	Type        *string             `json:"type,omitempty"`
@@ -27,10 +35,25 @@ type connection struct {
 	Host        string              `json:"host"`
 	Port        string              `json:"port"`
 	NumericPort int                 `json:"numeric_port"`
+	Hosts       []Endpoint          `json:"hosts,omitempty"`
 	Database    string              `json:"database"`
+	Path        string              `json:"path,omitempty"`
 	Properties  map[string][]string `json:"properties,omitempty"`
+
+	properties []Property
+	origins    map[string]string
+}
+
+// Endpoint is one host of a connection that names several.
+type Endpoint struct {
+	Host string `json:"host"`
+	Port string `json:"port"`
 }
 
+// Connection names the connection type for code outside the package, such as
+// the functions passed to RegisterFormat.
+type Connection = connection
+
 func (c *connection) IsFor(t string, sensitive ...bool) bool {
 	if c.Type == nil {
 		return false
@@ -43,14 +66,81 @@ func (c *connection) IsFor(t string, sensitive ...bool) bool {
 	return t == *c.Type
 }
 
+func (c *connection) isForAny(types ...string) bool {
+	for _, t := range types {
+		if c.IsFor(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Address returns host:port, with an IPv6 host in brackets, or the path of a
+// Unix domain socket.
 func (c *connection) Address() string {
+	if socket := c.SocketPath(); socket != "" {
+		return socket
+	}
+
 	if c.Port != "" {
-		return fmt.Sprintf("%s:%s", c.Host, c.Port)
+		// brackets an IPv6 host: [::1]:5432
+		return net.JoinHostPort(c.Host, c.Port)
 	}
 
 	return c.Host
 }
 
+// Endpoints returns every host with its port: Hosts when the input named more
+// than one, otherwise Host and Port.
+func (c *connection) Endpoints() []Endpoint {
+	if len(c.Hosts) > 0 {
+		return c.Hosts
+	}
+
+	if c.Host == "" && c.Port == "" {
+		return nil
+	}
+
+	return []Endpoint{{Host: c.Host, Port: c.Port}}
+}
+
+// withDefaultPort returns a copy of endpoints where every host without a port,
+// other than a socket, has port. Every port must be between 1 and 65535.
+func withDefaultPort(endpoints []Endpoint, port string) ([]Endpoint, error) {
+	defaulted := make([]Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if endpoint.Port == "" && !isSocketPath(endpoint.Host) {
+			endpoint.Port = port
+		}
+
+		if n, err := strconv.Atoi(endpoint.Port); endpoint.Port != "" && (err != nil || n < 1 || n > 65535) {
+			return nil, fmt.Errorf("port %q of host %q is not between 1 and 65535", endpoint.Port, endpoint.Host)
+		}
+
+		defaulted = append(defaulted, endpoint)
+	}
+
+	return defaulted, nil
+}
+
+// setEndpoints puts the first endpoint in Host and Port, and keeps them all in
+// Hosts when there is more than one.
+func (c *connection) setEndpoints(endpoints []Endpoint) {
+	if len(endpoints) == 0 {
+		return
+	}
+
+	c.Host = endpoints[0].Host
+	if endpoints[0].Port != "" {
+		c.assign(keyPort, endpoints[0].Port)
+	}
+
+	if len(endpoints) > 1 {
+		c.Hosts = endpoints
+	}
+}
+
 func (c *connection) HasUsername() bool {
 	return c.Username != nil
 }
@@ -86,143 +176,428 @@ func (c *connection) GetProperties(key string) []string {
 	return nil
 }
 
-func newConnection(data map[string]interface{}) (*connection, error) {
-	var b []byte
-	var err error
-	if b, err = json.Marshal(data); err == nil {
-		c := &connection{}
-		if err = json.Unmarshal(b, c); err == nil {
-			return c, err
+type Resolver func(c *connection) error
+
+type parser struct {
+	delimiters    []string
+	collapseSpace bool
+	separator     string
+	trimValues    bool
+	quotes        []rune
+	escape        rune
+	strict        bool
+	aliases       map[string]string
+	normalizeHost bool
+	defaults      *connection
+	resolvers     []Resolver
+}
+
+func (p *parser) resolve(c *connection, err error) (*connection, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if p.defaults != nil {
+		c = p.defaults.Overlay(c)
+	}
+
+	// a file database read from pairs or variables names its file in database
+	if c.Path == "" && c.isFileDatabase() {
+		c.Path = c.Database
+	}
+
+	for _, resolver := range p.resolvers {
+		if err = resolver(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.normalizeHost {
+		if err = c.normalizeHosts(); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil, err
+	return c, nil
 }
 
-type parser struct {
-	delimiter rune
+func (p *parser) FromUrl(input string) (*connection, error) {
+	return p.resolve(p.fromUrl(input))
 }
 
-func (p *parser) Delimiter(delimiter rune) *parser {
-	p.delimiter = delimiter
+func (p *parser) fromUrl(input string) (*connection, error) {
+	if scheme, rest, ok := strings.Cut(input, ":"); ok && isFileScheme(scheme) {
+		return p.fromFileURL(scheme, rest)
+	}
 
-	return p
-}
+	input, err := cutURLList(input)
+	if err != nil {
+		return nil, err
+	}
+
+	input, hosts, err := cutURLHosts(input)
+	if err != nil {
+		return nil, err
+	}
+
+	input, socket, err := cutSocketHost(input)
+	if err != nil {
+		return nil, err
+	}
 
-func (p *parser) FromUrl(input string) (*connection, error) {
 	u, err := url.Parse(input)
 	if err != nil {
 		return nil, err
 	}
 
-	data := make(map[string]interface{})
+	c := &connection{Host: socket}
 
 	if u.Scheme != "" {
-		data[keyType] = u.Scheme
+		c.Type = &u.Scheme
 	}
 
 	if u.User != nil {
-		data[keyUsername] = u.User.Username()
+		username := u.User.Username()
+		c.Username = &username
 
 		if password, ok := u.User.Password(); ok {
-			data[keyPassword] = password
+			c.Password = &password
 		}
 	}
 
-	if u.Host != "" {
-		data[keyHost] = u.Hostname()
+	if u.Host != "" && socket == "" {
+		c.Host = u.Hostname()
 	}
 
 	if port := u.Port(); port != "" {
-		data[keyPort] = port
-		if numericPort, err := strconv.Atoi(port); err == nil {
-			data[keyNumericPort] = numericPort
+		c.assign(keyPort, port)
+	}
+
+	c.setEndpoints(hosts)
+
+	c.Database = strings.TrimLeft(u.Path, "/")
+	if c.isAMQP() {
+		// the path is the vhost, where %2F is a vhost of its own and a bare
+		// "/" names the empty one; Path stays escaped so that AMQP can tell
+		// /a%2Fb from /a/b
+		c.Path, c.Database = u.EscapedPath(), strings.TrimPrefix(u.Path, "/")
+	}
+
+	// same as u.Query(), but keeps the order of the keys
+	_ = c.addQuery(u.RawQuery)
+
+	return c, nil
+}
+
+// urlHostSpan returns the offsets of the host and port of a URL, after the
+// user info: "db:5432" in postgres://alice@db:5432/app.
+func urlHostSpan(input string) (int, int, bool) {
+	start := strings.Index(input, "//")
+	if start < 0 || strings.ContainsAny(input[:start], "/?#") || (start > 0 && input[start-1] != ':') {
+		return 0, 0, false
+	}
+
+	start += len("//")
+	end := len(input)
+	if i := strings.IndexAny(input[start:], "/?#"); i >= 0 {
+		end = start + i
+	}
+
+	if at := strings.LastIndexByte(input[start:end], '@'); at >= 0 {
+		start += at + 1
+	}
+
+	return start, end, true
+}
+
+// cutURLHosts takes a list of hosts out of the authority of a URL, as in
+// mongodb://h1:27017,h2:27018/db, which net/url reads as a single host. It
+// returns the URL without them and the endpoints.
+func cutURLHosts(input string) (string, []Endpoint, error) {
+	start, end, ok := urlHostSpan(input)
+	if !ok || !strings.Contains(input[start:end], ",") {
+		return input, nil, nil
+	}
+
+	var endpoints []Endpoint
+	for _, address := range strings.Split(input[start:end], ",") {
+		host, port, _ := cutPort(address)
+		if strings.TrimFunc(port, unicode.IsDigit) != "" {
+			return "", nil, fmt.Errorf("parse %q: invalid port %q after host", input, ":"+port)
+		}
+
+		host, err := url.PathUnescape(trimBrackets(host))
+		if err != nil {
+			return "", nil, fmt.Errorf("parse %q: %w", input, err)
 		}
+
+		if host == "" {
+			return "", nil, fmt.Errorf("parse %q: empty host in %q", input, input[start:end])
+		}
+
+		endpoints = append(endpoints, Endpoint{Host: host, Port: port})
+	}
+
+	return input[:start] + input[end:], endpoints, nil
+}
+
+// cutPort splits host:port, or [host]:port, like net.SplitHostPort, but an
+// address without a port is all host. The host keeps its brackets.
+func cutPort(address string) (string, string, bool) {
+	if strings.HasPrefix(address, "[") {
+		if end := strings.IndexByte(address, ']'); end >= 0 && strings.HasPrefix(address[end+1:], ":") {
+			return address[:end+1], address[end+2:], true
+		}
+
+		return address, "", false
+	}
+
+	if strings.Count(address, ":") != 1 {
+		return address, "", false
+	}
+
+	return strings.Cut(address, ":")
+}
+
+// urlListEnd returns the offset where the authority of the last URL ends in a
+// list of full URLs, such as nats://n1:4222,nats://n2:4222/, or 0 when the
+// input is not one. Only the last URL may have a path.
+func urlListEnd(input string) int {
+	end := len(input)
+	if i := strings.IndexAny(input, "?#"); i >= 0 {
+		end = i
+	}
+
+	first, others, ok := strings.Cut(input[:end], ",")
+	if !ok {
+		return 0
+	}
+
+	prefix := urlSchemePattern.FindString(first)
+	if prefix == "" || strings.Contains(first[len(prefix):], "/") {
+		return 0
+	}
+
+	offset := len(first)
+	for _, element := range strings.Split(others, ",") {
+		offset++
+
+		next := urlSchemePattern.FindString(element)
+		if next == "" {
+			return 0
+		}
+
+		if i := strings.IndexByte(element[len(next):], '/'); i >= 0 {
+			if offset+len(element) != end {
+				return 0
+			}
+
+			return offset + len(next) + i
+		}
+
+		offset += len(element)
 	}
 
-	if path := strings.TrimLeft(u.Path, "/"); path != "" {
-		data[keyDatabase] = path
+	return offset
+}
+
+// cutURLList turns a list of full URLs into one URL with a list of hosts:
+// nats://alice@n1:4222,nats://n2:4222 gives nats://alice@n1:4222,n2:4222. The
+// URLs must share their scheme, and the credentials are those of the first.
+func cutURLList(input string) (string, error) {
+	end := urlListEnd(input)
+	if end == 0 {
+		return input, nil
 	}
 
-	if queries := u.Query(); len(queries) > 0 {
-		data[keyProperties] = map[string][]string(queries)
+	elements := strings.Split(input[:end], ",")
+	prefix := urlSchemePattern.FindString(elements[0])
+
+	credentials := ""
+	if at := strings.LastIndexByte(elements[0], '@'); at >= 0 {
+		credentials = elements[0][len(prefix):at]
 	}
 
-	return newConnection(data)
+	var b strings.Builder
+	b.WriteString(elements[0])
+
+	for _, element := range elements[1:] {
+		next := urlSchemePattern.FindString(element)
+		if !strings.EqualFold(next, prefix) {
+			return "", fmt.Errorf("parse %q: %q does not start with %s", input, element, prefix)
+		}
+
+		authority := element[len(next):]
+		if at := strings.LastIndexByte(authority, '@'); at >= 0 {
+			if authority[:at] != credentials {
+				return "", fmt.Errorf("parse %q: %q has other credentials than the first URL", input, element)
+			}
+
+			authority = authority[at+1:]
+		}
+
+		b.WriteByte(',')
+		b.WriteString(authority)
+	}
+
+	b.WriteString(input[end:])
+
+	return b.String(), nil
 }
 
 func (p *parser) FromPair(input string) (*connection, error) {
-	reader := csv.NewReader(strings.NewReader(input))
-	reader.Comma = p.delimiter
-	reader.LazyQuotes = true
+	return p.resolve(p.fromPair(input))
+}
 
-	columns, err := reader.Read()
+func (p *parser) fromPair(input string) (*connection, error) {
+	fields, err := p.lexPairs(input)
 	if err != nil {
 		return nil, err
 	}
 
-	data := make(map[string]interface{})
-	properties := make(map[string][]string)
+	c := &connection{}
 
-	for _, column := range columns {
-		if column == "" {
+	for _, field := range fields {
+		if field.text() == "" {
 			continue
 		}
 
-		key, value, _ := strings.Cut(column, "=")
-		key = strings.TrimSpace(key)
-		switch key {
-		case keyType, "scheme":
-			data[keyType] = value
-		case keyUsername, "user":
-			data[keyUsername] = value
-		case keyPassword, "pass":
-			data[keyPassword] = value
-		case keyHost:
-			data[keyHost] = value
-		case keyPort:
-			data[keyPort] = value
-			if numericPort, err := strconv.Atoi(value); err == nil {
-				data[keyNumericPort] = numericPort
+		key, value, ok := field.split(p.separator)
+		if !ok && p.strict {
+			return nil, &SyntaxError{Offset: field.start, Message: fmt.Sprintf("missing %q in %q", p.separator, field.text())}
+		}
+
+		if p.trimValues {
+			value.segments = trimSegments(value.segments)
+		}
+
+		if _, err = p.assign(c, strings.TrimSpace(key.text()), value.text()); err != nil {
+			return nil, &SyntaxError{Offset: field.start, Message: err.Error()}
+		}
+	}
+
+	return c, nil
+}
+
+func (p *parser) canonical(key string) string {
+	if canonical, ok := p.aliases[key]; ok {
+		return canonical
+	}
+
+	return key
+}
+
+func (p *parser) assign(c *connection, key string, value string) (string, error) {
+	return p.assignRaw(c, key, key, value)
+}
+
+// assignRaw is assign for a key that was normalised before the lookup; raw is
+// the key as written, and is kept for properties.
+func (p *parser) assignRaw(c *connection, key string, raw string, value string) (string, error) {
+	key = p.canonical(key)
+
+	if p.strict {
+		if key == keyPort {
+			if _, err := strconv.Atoi(value); err != nil {
+				return "", fmt.Errorf("port %q is not a number", value)
 			}
-		case keyDatabase, "dbname", "db":
-			data[keyDatabase] = value
-		default:
-			properties[key] = append(properties[key], value)
+		}
+
+		if c.isSet(key) && !c.HasProperty(key) {
+			return "", fmt.Errorf("%s is set more than once", key)
+		}
+	}
+
+	if !isField(key) {
+		c.addProperty(key, value, raw)
+
+		return key, nil
+	}
+
+	return c.assign(key, value), nil
+}
+
+func isField(key string) bool {
+	switch key {
+	case keyType, keyUsername, keyPassword, keyHost, keyPort, keyDatabase:
+		return true
+	}
+
+	return false
+}
+
+func (c *connection) isSet(field string) bool {
+	for _, set := range c.fields() {
+		if set == field {
+			return true
 		}
 	}
 
-	if len(properties) > 0 {
-		data[keyProperties] = properties
+	return false
+}
+
+func (c *connection) assign(key string, value string) string {
+	switch key {
+	case keyType:
+		c.Type = &value
+	case keyUsername:
+		c.Username = &value
+	case keyPassword:
+		c.Password = &value
+	case keyHost:
+		// host=[::1] is the same host as host=::1
+		c.Host = trimBrackets(value)
+	case keyPort:
+		c.Port = value
+		c.NumericPort, _ = strconv.Atoi(value)
+	case keyDatabase:
+		c.Database = value
+	default:
+		c.addProperty(key, value)
 	}
 
-	return newConnection(data)
+	return key
 }
 
 func (p *parser) Parse(input string) (*connection, error) {
+	return p.resolve(p.parse(input))
+}
+
+func (p *parser) parse(input string) (*connection, error) {
 	if input == "" {
 		return &connection{}, nil
 	}
 
-	if strings.Contains(input, "://") || strings.HasPrefix(input, "//") {
-		return p.FromUrl(input)
+	return p.parseAs(p.Detect(input), input)
+}
+
+func NewParser(opts ...Option) *parser {
+	p := &parser{
+		delimiters: []string{string(defaultDelimiter)},
+		separator:  defaultSeparator,
+		quotes:     defaultQuotes,
+		aliases:    make(map[string]string, len(defaultAliases)),
 	}
 
-	return p.FromPair(input)
-}
+	for alias, key := range defaultAliases {
+		p.aliases[alias] = key
+	}
 
-func NewParser() *parser {
-	return &parser{
-		delimiter: defaultDelimiter,
+	for _, opt := range opts {
+		opt(p)
 	}
+
+	return p
 }
 
-func Parse(input string, delimiters ...rune) (*connection, error) {
-	p := NewParser()
+// defaultParser serves the package-level functions. Parsing never changes a
+// parser, so it is shared rather than built on every call.
+var defaultParser = NewParser()
 
+func Parse(input string, delimiters ...rune) (*connection, error) {
 	if len(delimiters) > 0 {
-		p = p.Delimiter(delimiters[0])
+		return NewParser(WithDelimiter(delimiters[0])).Parse(input)
 	}
 
-	return p.Parse(input)
+	return defaultParser.Parse(input)
 }
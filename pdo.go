@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var pdoAliases = map[string]string{
+	"host":        keyHost,
+	"unix_socket": keyHost,
+	"port":        keyPort,
+	"dbname":      keyDatabase,
+	"user":        keyUsername,
+	"password":    keyPassword,
+}
+
+// pdoDrivers are the PHP drivers whose DSN is a list of key=value pairs.
+// sqlite takes a path instead, which is read like a sqlite: URL.
+var pdoDrivers = []string{"mysql", "pgsql", "oci", "cubrid"}
+
+// pdoDriverOf names the driver of the types that PDO calls otherwise.
+var pdoDriverOf = map[string]string{
+	"mariadb":    "mysql",
+	"postgres":   "pgsql",
+	"postgresql": "pgsql",
+	"oracle":     "oci",
+}
+
+// driver:key=value;key=value
+func detectPDO(p *parser, input string) float64 {
+	driver, rest, ok := strings.Cut(input, ":")
+	if !ok || !isPDODriver(driver) || strings.HasPrefix(rest, "//") {
+		return 0
+	}
+
+	key, _, ok := strings.Cut(rest, "=")
+	if !ok || strings.TrimSpace(key) == "" || strings.ContainsAny(key, ";/") {
+		return 0
+	}
+
+	return 0.95
+}
+
+func isPDODriver(driver string) bool {
+	for _, d := range pdoDrivers {
+		if strings.EqualFold(driver, d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) fromPDO(input string) (*connection, error) {
+	driver, _, ok := strings.Cut(input, ":")
+	if !ok || driver == "" {
+		return nil, &SyntaxError{Offset: len(input), Message: "missing \":\" after the driver name"}
+	}
+
+	driver = strings.ToLower(driver)
+	if driver == "sqlite" {
+		return p.fromUrl(input)
+	}
+
+	c := &connection{}
+	c.assign(keyType, driver)
+
+	err := scanPDO(input, driver, func(offset int, field string) error {
+		return p.assignPDO(c, offset, field)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// scanPDO calls each with the offset and the text of the fields after the
+// driver name, and stops at the first error.
+func scanPDO(input string, driver string, each func(offset int, field string) error) error {
+	at := len(driver) + 1
+	for _, column := range strings.Split(input[at:], ";") {
+		// pgsql hands the DSN to libpq, which also splits on spaces
+		fields := []string{column}
+		if driver == "pgsql" {
+			fields = strings.Fields(column)
+		}
+
+		offset := at
+		for _, field := range fields {
+			offset += strings.Index(input[offset:], field)
+
+			if err := each(offset, field); err != nil {
+				return err
+			}
+		}
+
+		at += len(column) + 1
+	}
+
+	return nil
+}
+
+func (p *parser) assignPDO(c *connection, offset int, field string) error {
+	key, value, ok := strings.Cut(field, "=")
+	if key = strings.TrimSpace(key); !ok {
+		if key != "" {
+			return &SyntaxError{Offset: offset, Message: fmt.Sprintf("missing \"=\" in %q", key)}
+		}
+
+		return nil
+	}
+
+	if key == "" {
+		return &SyntaxError{Offset: offset, Message: "attribute has no key"}
+	}
+
+	if _, err := p.assignRaw(c, pdoKey(key), key, strings.TrimSpace(value)); err != nil {
+		return &SyntaxError{Offset: offset, Message: err.Error()}
+	}
+
+	return nil
+}
+
+// pdoKey maps a DSN key to the key it is stored under; the driver keys are
+// lower case, so other cases are only folded.
+func pdoKey(raw string) string {
+	name := strings.ToLower(raw)
+	if alias, ok := pdoAliases[name]; ok {
+		return alias
+	}
+
+	return name
+}
+
+func renderPDO(c *connection) (string, error) {
+	if c.Type == nil {
+		return "", errors.New("pdo: the connection has no driver")
+	}
+
+	driver := strings.ToLower(*c.Type)
+	if d, ok := pdoDriverOf[driver]; ok {
+		driver = d
+	}
+
+	if driver == "sqlite" {
+		return "sqlite:" + c.Path, nil
+	}
+
+	if !isPDODriver(driver) {
+		return "", fmt.Errorf("pdo: %q is not a PDO driver", *c.Type)
+	}
+
+	if err := oneHost(c, "pdo", "PDO"); err != nil {
+		return "", err
+	}
+
+	var pairs []string
+	write := func(key string, value string) error {
+		if strings.Contains(value, ";") {
+			return fmt.Errorf("pdo: the %s value holds a \";\"", key)
+		}
+
+		pairs = append(pairs, key+"="+value)
+
+		return nil
+	}
+
+	host := "host"
+	if c.SocketPath() != "" && driver == "mysql" {
+		host = "unix_socket"
+	}
+
+	fields := []struct {
+		key   string
+		value string
+		set   bool
+	}{
+		{host, c.Host, c.Host != ""},
+		{"port", c.Port, c.Port != ""},
+		{"dbname", c.Database, c.Database != ""},
+		{"user", stringOf(c.Username), c.Username != nil},
+		{"password", stringOf(c.Password), c.Password != nil},
+	}
+
+	for _, field := range fields {
+		if !field.set {
+			continue
+		}
+
+		if err := write(field.key, field.value); err != nil {
+			return "", err
+		}
+	}
+
+	for _, property := range c.PropertyList() {
+		if err := write(property.name(), property.Value); err != nil {
+			return "", err
+		}
+	}
+
+	return driver + ":" + strings.Join(pairs, ";"), nil
+}
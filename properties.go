@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Property is one key/value pair of a connection, in input order.
+type Property struct {
+	Key   string
+	Value string
+	// Raw is the key as it was written in the input, before aliases and case
+	// folding were applied.
+	Raw string
+}
+
+// name is the key renderers write: the original spelling, unless an alias
+// turned it into a different key.
+func (p Property) name() string {
+	if strings.EqualFold(p.Raw, p.Key) {
+		return p.Raw
+	}
+
+	return p.Key
+}
+
+func (c *connection) addProperty(key string, value string, raw ...string) {
+	if c.Properties == nil {
+		c.Properties = make(map[string][]string)
+	}
+
+	c.Properties[key] = append(c.Properties[key], value)
+
+	property := Property{Key: key, Value: value, Raw: key}
+	if len(raw) > 0 {
+		property.Raw = raw[0]
+	}
+
+	c.properties = append(c.properties, property)
+}
+
+func (c *connection) deleteProperty(key string) {
+	delete(c.Properties, key)
+	if len(c.Properties) == 0 {
+		c.Properties = nil
+	}
+
+	var kept []Property
+	for _, property := range c.properties {
+		if property.Key != key {
+			kept = append(kept, property)
+		}
+	}
+
+	c.properties = kept
+}
+
+// addQuery adds the pairs of a URL query in order. Like url.ParseQuery, it
+// skips the pairs it cannot decode and returns the first error.
+func (c *connection) addQuery(query string) error {
+	var first error
+	scanQuery(query, func(pair queryPair) {
+		switch {
+		case pair.err == nil:
+			c.addProperty(pair.key, pair.value, pair.raw)
+		case first == nil:
+			first = pair.err
+		}
+	})
+
+	return first
+}
+
+// queryPair is a pair of a URL query: where it starts in the query, its text,
+// the key as written, and the decoded key and value. err is why url.ParseQuery
+// would skip it.
+type queryPair struct {
+	offset    int
+	text      string
+	raw       string
+	separated bool
+	key       string
+	value     string
+	err       error
+}
+
+// scanQuery calls each for the pairs of a URL query, in order, leaving out the
+// empty ones.
+func scanQuery(query string, each func(pair queryPair)) {
+	offset := 0
+	for offset <= len(query) {
+		text, _, _ := strings.Cut(query[offset:], "&")
+		pair := queryPair{offset: offset, text: text}
+		offset += len(text) + 1
+
+		if strings.Contains(text, ";") {
+			pair.err = errors.New("invalid semicolon separator in query")
+			each(pair)
+			continue
+		}
+
+		if text == "" {
+			continue
+		}
+
+		var rawValue string
+		pair.raw, rawValue, pair.separated = strings.Cut(text, "=")
+		if pair.key, pair.err = url.QueryUnescape(pair.raw); pair.err == nil {
+			pair.value, pair.err = url.QueryUnescape(rawValue)
+		}
+
+		each(pair)
+	}
+}
+
+// PropertyList returns the properties in the order they were read. Values
+// added to the Properties map by hand come last, sorted by key; values removed
+// from it are left out.
+func (c *connection) PropertyList() []Property {
+	if len(c.Properties) == 0 {
+		return nil
+	}
+
+	list := make([]Property, 0, len(c.properties))
+	used := make(map[string]int, len(c.Properties))
+
+	for _, property := range c.properties {
+		values, n := c.Properties[property.Key], used[property.Key]
+		if n < len(values) && values[n] == property.Value {
+			list = append(list, property)
+			used[property.Key]++
+		}
+	}
+
+	keys := make([]string, 0, len(c.Properties))
+	for key := range c.Properties {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range c.Properties[key][used[key]:] {
+			list = append(list, Property{Key: key, Value: value, Raw: key})
+		}
+	}
+
+	return list
+}
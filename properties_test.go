@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropertyListKeepsInputOrder(t *testing.T) {
+	conn, err := Parse("postgres://example.com/app?sslmode=require&application_name=api&sslmode=prefer&search%20path=public")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Property{
+		{Key: "sslmode", Value: "require", Raw: "sslmode"},
+		{Key: "application_name", Value: "api", Raw: "application_name"},
+		{Key: "sslmode", Value: "prefer", Raw: "sslmode"},
+		{Key: "search path", Value: "public", Raw: "search%20path"},
+	}, conn.PropertyList())
+
+	conn, err = NewParser(WithAlias("sslmode", "ssl_mode")).Parse("zone=eu ssl_mode=require app=api")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Property{
+		{Key: "zone", Value: "eu", Raw: "zone"},
+		{Key: "sslmode", Value: "require", Raw: "ssl_mode"},
+		{Key: "app", Value: "api", Raw: "app"},
+	}, conn.PropertyList())
+
+	assert.Nil(t, (&connection{}).PropertyList())
+}
+
+func TestPropertyListFollowsTheMap(t *testing.T) {
+	conn, err := Parse("c=1 b=2 a=3 b=4")
+	assert.NoError(t, err)
+
+	delete(conn.Properties, "c")
+	conn.Properties["b"] = []string{"2", "5"}
+	conn.Properties["aa"] = []string{"6"}
+
+	assert.Equal(t, []Property{
+		{Key: "b", Value: "2", Raw: "b"},
+		{Key: "a", Value: "3", Raw: "a"},
+		{Key: "aa", Value: "6", Raw: "aa"},
+		{Key: "b", Value: "5", Raw: "b"},
+	}, conn.PropertyList())
+}
+
+func TestRenderKeepsPropertyOrder(t *testing.T) {
+	conn, err := Parse("Server=db.example.com;Initial Catalog=app;TrustServerCertificate=True;Application Name=api;Encrypt=yes")
+	assert.NoError(t, err)
+
+	rendered, err := conn.Render(FormatADONET)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Server=db.example.com;Database=app;TrustServerCertificate=True;Application Name=api;Encrypt=yes", rendered)
+
+	conn, err = Parse("mysql://db.example.com/app?tls=true&charset=utf8mb4&collation=utf8mb4_bin&parseTime=true")
+	assert.NoError(t, err)
+
+	rendered, err = conn.Render(FormatURL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "mysql://db.example.com/app?tls=true&charset=utf8mb4&collation=utf8mb4_bin&parseTime=true", rendered)
+
+	rendered, err = conn.Render(FormatMySQLDSN)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp(db.example.com)/app?tls=true&charset=utf8mb4&collation=utf8mb4_bin&parseTime=true", rendered)
+}
+
+func TestOverlayKeepsPropertyOrder(t *testing.T) {
+	base, _ := Parse("postgres://example.com/app?sslmode=disable&connect_timeout=5&application_name=base")
+	layer, _ := Parse("application_name=api sslmode=require")
+
+	assert.Equal(t, []Property{
+		{Key: "connect_timeout", Value: "5", Raw: "connect_timeout"},
+		{Key: "application_name", Value: "api", Raw: "application_name"},
+		{Key: "sslmode", Value: "require", Raw: "sslmode"},
+	}, base.Overlay(layer).PropertyList())
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+func renderURL(c *connection) (string, error) {
+	if c.isFileDatabase() {
+		return renderFileURL(c), nil
+	}
+
+	var addresses []string
+	for _, endpoint := range c.Endpoints() {
+		addresses = append(addresses, hostPort(endpoint.Host, endpoint.Port))
+	}
+
+	// several hosts are written as a list: mongodb://h1:27017,h2:27018/db
+	u := &url.URL{Host: strings.Join(addresses, ",")}
+
+	if c.Type != nil {
+		u.Scheme = *c.Type
+	}
+
+	if c.Password != nil {
+		u.User = url.UserPassword(stringOf(c.Username), *c.Password)
+	} else if c.Username != nil {
+		u.User = url.User(*c.Username)
+	}
+
+	switch {
+	case c.isAMQP() && (c.Database != "" || c.Path == "/"):
+		u.Path, u.RawPath = "/"+c.Database, "/"+url.PathEscape(c.Database)
+
+		// the path as written, while it still names the same vhost
+		if path, err := url.PathUnescape(c.Path); err == nil && path == u.Path {
+			u.RawPath = c.Path
+		}
+	case c.Database != "":
+		u.Path = "/" + c.Database
+	}
+
+	u.RawQuery = encodeQuery(c.PropertyList())
+
+	return u.String(), nil
+}
+
+// renderPairs writes the connection for a parser with the default settings.
+func renderPairs(c *connection) (string, error) {
+	if err := oneHost(c, "pairs", "delimited"); err != nil {
+		return "", err
+	}
+
+	var pairs []string
+
+	write := func(key string, value string) {
+		pairs = append(pairs, key+defaultSeparator+quotePair(value))
+	}
+
+	if c.Type != nil {
+		write(keyType, *c.Type)
+	}
+
+	if c.Username != nil {
+		write(keyUsername, *c.Username)
+	}
+
+	if c.Password != nil {
+		write(keyPassword, *c.Password)
+	}
+
+	if c.Host != "" {
+		write(keyHost, c.Host)
+	}
+
+	if c.Port != "" {
+		write(keyPort, c.Port)
+	}
+
+	if c.Database != "" {
+		write(keyDatabase, c.Database)
+	}
+
+	for _, property := range c.PropertyList() {
+		write(property.name(), property.Value)
+	}
+
+	return strings.Join(pairs, string(defaultDelimiter)), nil
+}
+
+// oneHost fails for a connection with several hosts in a format that names a
+// single server, rather than drop all but the first.
+func oneHost(c *connection, prefix string, format string) error {
+	if len(c.Hosts) > 1 {
+		return fmt.Errorf("%s: a list of hosts has no %s form", prefix, format)
+	}
+
+	return nil
+}
+
+func quotePair(value string) string {
+	if !strings.ContainsAny(value, " \t\"") {
+		return value
+	}
+
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+func hostPort(host string, port string) string {
+	if port != "" {
+		return net.JoinHostPort(host, port)
+	}
+
+	if strings.Contains(host, ":") {
+		return "[" + host + "]"
+	}
+
+	return host
+}
+
+// encodeQuery is url.Values.Encode without the sorting.
+func encodeQuery(properties []Property) string {
+	pairs := make([]string, 0, len(properties))
+	for _, property := range properties {
+		pairs = append(pairs, url.QueryEscape(property.name())+"="+url.QueryEscape(property.Value))
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func stringOf(v *string) string {
+	if v == nil {
+		return ""
+	}
+
+	return *v
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const networkTCP = "tcp"
+const networkUnix = "unix"
+
+// Network returns "unix" when the connection goes through a Unix domain
+// socket, and "tcp" otherwise.
+func (c *connection) Network() string {
+	if c.SocketPath() != "" {
+		return networkUnix
+	}
+
+	return networkTCP
+}
+
+// SocketPath returns the Unix domain socket the connection goes through, or
+// "" for a TCP connection. Postgres names the directory that holds the
+// socket, and takes the file name from the port; MySQL names the file itself.
+//
+// A host is a socket when it is an absolute path, or when the net (MySQL DSN)
+// or protocol (ADO.NET) property says so. A MySQL connection without a host
+// uses the socket property, which MySQLOptionFile reads from a my.cnf file.
+func (c *connection) SocketPath() string {
+	if isSocketPath(c.Host) {
+		return c.Host
+	}
+
+	if c.Host == "" && c.isForAny(optionFileTypes...) {
+		return c.GetProperty("socket")
+	}
+
+	if strings.EqualFold(c.GetProperty("net"), networkUnix) || strings.EqualFold(c.GetProperty("protocol"), networkUnix) {
+		return c.Host
+	}
+
+	return ""
+}
+
+func isSocketPath(host string) bool {
+	return strings.HasPrefix(host, "/")
+}
+
+// cutSocketHost takes a percent-encoded socket path out of the authority of a
+// URL, as in postgres://%2Fvar%2Frun%2Fpostgresql/db, which net/url refuses
+// as a host. It returns the URL without it and the unescaped path.
+func cutSocketHost(input string) (string, string, error) {
+	start, end, ok := urlHostSpan(input)
+	if !ok || end-start < 3 || !strings.EqualFold(input[start:start+3], "%2f") {
+		return input, "", nil
+	}
+
+	host := input[start:end]
+	if colon := strings.LastIndexByte(host, ':'); colon >= 0 {
+		host = host[:colon]
+	}
+
+	path, err := url.PathUnescape(host)
+	if err != nil {
+		return "", "", fmt.Errorf("parse %q: %w", input, err)
+	}
+
+	return input[:start] + input[start+len(host):], path, nil
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSocket(t *testing.T) {
+	checks := map[string]struct {
+		input  string
+		socket string
+		port   string
+	}{
+		"postgres url":               {"postgres://alice@%2Fvar%2Frun%2Fpostgresql:5432/app", "/var/run/postgresql", "5432"},
+		"postgres url in lower case": {"postgres://%2fvar%2frun%2fpostgresql/app", "/var/run/postgresql", ""},
+		"url without a scheme":       {"//%2Ftmp%2Fx.sock/app", "/tmp/x.sock", ""},
+		"postgres pairs":             {"host=/var/run/postgresql port=5433 dbname=app", "/var/run/postgresql", "5433"},
+		"mysql dsn":                  {"alice@unix(/var/run/mysqld/mysqld.sock)/app", "/var/run/mysqld/mysqld.sock", ""},
+		"mysql dsn, relative path":   {"alice@unix(mysql.sock)/app", "mysql.sock", ""},
+		"ado.net":                    {"Server=mysql.sock;Protocol=Unix;Database=app", "mysql.sock", ""},
+		"tcp":                        {"postgres://db.example.com:5432/app", "", "5432"},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			conn, err := Parse(check.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.socket, conn.SocketPath())
+			assert.Equal(t, check.port, conn.Port)
+
+			if check.socket == "" {
+				assert.Equal(t, "tcp", conn.Network())
+				assert.Equal(t, "db.example.com:5432", conn.Address())
+			} else {
+				assert.Equal(t, "unix", conn.Network())
+				assert.Equal(t, check.socket, conn.Address())
+			}
+		})
+	}
+
+	_, err := Parse("postgres://%2Fvar%zz/app")
+	assert.EqualError(t, err, `parse "postgres://%2Fvar%zz/app": invalid URL escape "%zz"`)
+}
+
+func TestRenderSocket(t *testing.T) {
+	checks := map[string]struct {
+		input    string
+		expected map[Format]string
+	}{
+		"postgres": {
+			input: "type=postgres host=/var/run/postgresql port=5432 dbname=app",
+			expected: map[Format]string{
+				FormatURL:    "postgres://%2Fvar%2Frun%2Fpostgresql:5432/app",
+				FormatPairs:  "type=postgres host=/var/run/postgresql port=5432 database=app",
+				FormatADONET: "Server=/var/run/postgresql;Port=5432;Database=app",
+				FormatODBC:   "SERVER=/var/run/postgresql;PORT=5432;DATABASE=app",
+			},
+		},
+		"mysql": {
+			input: "alice@unix(/tmp/mysql.sock)/app",
+			expected: map[Format]string{
+				FormatURL:      "mysql://alice@%2Ftmp%2Fmysql.sock/app",
+				FormatPairs:    "type=mysql username=alice host=/tmp/mysql.sock database=app",
+				FormatADONET:   "Server=/tmp/mysql.sock;Protocol=Unix;Database=app;User ID=alice",
+				FormatMySQLDSN: "alice@unix(/tmp/mysql.sock)/app",
+				FormatODBC:     "SERVER=/tmp/mysql.sock;DATABASE=app;UID=alice",
+			},
+		},
+		"mysql, relative path": {
+			input: "alice@unix(mysql.sock)/app",
+			expected: map[Format]string{
+				FormatURL:      "mysql://alice@mysql.sock/app?net=unix",
+				FormatMySQLDSN: "alice@unix(mysql.sock)/app",
+			},
+		},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			conn, err := Parse(check.input)
+			assert.NoError(t, err)
+
+			for format, expected := range check.expected {
+				rendered, err := conn.Render(format)
+
+				assert.NoError(t, err)
+				assert.Equal(t, expected, rendered, format)
+
+				parsed, err := ParseAs(format, rendered)
+
+				assert.NoError(t, err)
+				assert.Equal(t, conn.SocketPath(), parsed.SocketPath(), format)
+			}
+
+			for _, format := range []Format{FormatJDBC, FormatEZConnect, FormatTNS} {
+				_, err = conn.Render(format)
+				assert.Error(t, err, format)
+			}
+		})
+	}
+
+	conn, err := Parse("type=postgres host=/var/run/postgresql port=5432 dbname=app")
+	assert.NoError(t, err)
+
+	_, err = conn.Render(FormatMySQLDSN)
+	assert.EqualError(t, err, "mysql dsn: a unix socket has no port in the MySQL DSN form")
+}
+
+func TestDocumentSetSocket(t *testing.T) {
+	doc, err := ParseLossless("postgres://alice@db.example.com:5432/app")
+	assert.NoError(t, err)
+
+	assert.NoError(t, doc.Set("host", "/var/run/postgresql"))
+	assert.Equal(t, "postgres://alice@%2Fvar%2Frun%2Fpostgresql:5432/app", doc.String())
+
+	conn, err := doc.Connection()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/run/postgresql", conn.SocketPath())
+	assert.Equal(t, "5432", conn.Port)
+
+	for _, node := range doc.Nodes() {
+		if node.Kind == NodeHost {
+			assert.Equal(t, "/var/run/postgresql", node.Text)
+		}
+	}
+}
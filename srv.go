@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const srvSuffix = "+srv"
+
+// mongoTXTOptions are the only options a mongodb+srv TXT record may set.
+var mongoTXTOptions = []string{"authSource", "replicaSet", "loadBalanced"}
+
+// mongoSRVOptions are only allowed in a mongodb+srv string.
+var mongoSRVOptions = []string{"srvServiceName", "srvMaxHosts"}
+
+// DNSResolver looks up the records Resolve needs. *net.Resolver implements
+// it, and tests can pass a fake.
+type DNSResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Resolve expands a connection whose type ends in "+srv" into the hosts of
+// its SRV records, and returns the result as a new connection of the base
+// type: postgres+srv://db.example.com/app looks up _postgres._tcp.db.example.com.
+// A mongodb+srv connection follows the seed list discovery specification:
+// the hosts must share the domain of the SRV name, a TXT record may add
+// authSource, replicaSet and loadBalanced, srvMaxHosts keeps that many hosts
+// picked at random, srvServiceName and srvMaxHosts are dropped, and tls=true
+// is written out unless the string sets tls or ssl. Any other connection is
+// returned as a copy, except a mongodb:// one with an SRV option, which is an
+// error. A nil resolver uses net.DefaultResolver.
+func (c *connection) Resolve(ctx context.Context, resolver DNSResolver) (*connection, error) {
+	if c.Type == nil || len(*c.Type) <= len(srvSuffix) || !strings.EqualFold((*c.Type)[len(*c.Type)-len(srvSuffix):], srvSuffix) {
+		if c.IsFor("mongodb") {
+			if err := noMongoSRVOptions(c); err != nil {
+				return nil, err
+			}
+		}
+
+		return c.clone(), nil
+	}
+
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	scheme := (*c.Type)[:len(*c.Type)-len(srvSuffix)]
+	mongo := strings.EqualFold(scheme, "mongodb")
+
+	service := strings.ToLower(scheme)
+	if mongo {
+		if _, err := c.MongoDB(); err != nil {
+			return nil, err
+		}
+
+		service = firstOf(c.mongoOption("srvServiceName"), service)
+	} else if endpoints := c.Endpoints(); len(endpoints) != 1 || endpoints[0].Port != "" {
+		return nil, fmt.Errorf("%s takes one host without a port", *c.Type)
+	}
+
+	host := strings.TrimSuffix(c.Host, ".")
+	name := "_" + service + "._tcp." + host
+
+	_, records, err := resolver.LookupSRV(ctx, service, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", name, err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("resolve %s: no SRV records", name)
+	}
+
+	endpoints := make([]Endpoint, 0, len(records))
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		if mongo && !sharesSRVDomain(host, target) {
+			return nil, fmt.Errorf("resolve %s: host %q is not in the domain of %q", name, target, host)
+		}
+
+		endpoints = append(endpoints, Endpoint{Host: target, Port: strconv.Itoa(int(record.Port))})
+	}
+
+	resolved := c.clone()
+
+	if mongo {
+		if err = resolved.applyMongoTXT(ctx, resolver, host); err != nil {
+			return nil, err
+		}
+
+		// the TXT record may have added a replicaSet, loadBalanced=true or an
+		// authSource, so the merged options are checked again
+		options, err := resolved.MongoDB()
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", name, err)
+		}
+
+		if limit := options.SRVMaxHosts; limit > 0 && limit < len(endpoints) {
+			random := rand.New(rand.NewSource(time.Now().UnixNano()))
+			random.Shuffle(len(endpoints), func(i, j int) {
+				endpoints[i], endpoints[j] = endpoints[j], endpoints[i]
+			})
+
+			endpoints = endpoints[:limit]
+		}
+
+		// the SRV options mean nothing to a mongodb:// string
+		for _, property := range c.PropertyList() {
+			if canonicalOf(property.Key, mongoSRVOptions) != "" {
+				resolved.deleteProperty(property.Key)
+			}
+		}
+
+		// the +srv scheme implied TLS, the plain one does not
+		if _, ok := c.mongoOptionSet("tls"); !ok {
+			if _, ok = c.mongoOptionSet("ssl"); !ok {
+				resolved.addProperty("tls", "true")
+			}
+		}
+	}
+
+	resolved.Type = &scheme
+	resolved.Host, resolved.Port, resolved.NumericPort, resolved.Hosts = "", "", 0, nil
+	resolved.setEndpoints(endpoints)
+
+	origin := "dns:" + name
+	resolved.setOrigin(keyHost, origin)
+	resolved.setOrigin(keyPort, origin)
+
+	return resolved, nil
+}
+
+// applyMongoTXT adds the options of the TXT record of host. An option the
+// connection string sets already keeps its value, and no record at all is
+// not an error.
+func (c *connection) applyMongoTXT(ctx context.Context, resolver DNSResolver, host string) error {
+	records, err := resolver.LookupTXT(ctx, host)
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("resolve TXT %s: %w", host, err)
+	}
+
+	if len(records) > 1 {
+		return fmt.Errorf("resolve TXT %s: %d records, at most one is allowed", host, len(records))
+	}
+
+	if len(records) == 0 || records[0] == "" {
+		return nil
+	}
+
+	for _, pair := range strings.Split(records[0], "&") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("resolve TXT %s: %q is not a key=value pair", host, pair)
+		}
+
+		if canonicalOf(key, mongoTXTOptions) == "" {
+			return fmt.Errorf("resolve TXT %s: option %q is not one of %s", host, key, strings.Join(mongoTXTOptions, ", "))
+		}
+
+		if _, ok = c.mongoOptionSet(key); ok {
+			continue
+		}
+
+		decoded, err := url.QueryUnescape(value)
+		if err != nil {
+			return fmt.Errorf("resolve TXT %s: %w", host, err)
+		}
+
+		c.addProperty(key, decoded)
+		c.setOrigin(key, "dns:"+host)
+	}
+
+	return nil
+}
+
+// sharesSRVDomain reports whether target lies in the domain of the SRV host:
+// under its parent domain, or under the host itself when it has fewer than
+// three labels.
+func sharesSRVDomain(host string, target string) bool {
+	host, target = strings.ToLower(host), strings.ToLower(target)
+
+	domain := host
+	if strings.Count(host, ".") >= 2 {
+		domain = host[strings.IndexByte(host, '.')+1:]
+	}
+
+	return strings.HasSuffix(target, "."+domain)
+}
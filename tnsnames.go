@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const tnsNamesMaxDepth = 10
+
+// TNSNames resolves an Oracle connection whose host is an alias from a
+// tnsnames.ora file, such as the "orcl" in "scott/tiger@orcl". With an empty
+// path the file is looked up in $TNS_ADMIN, then in
+// $ORACLE_HOME/network/admin.
+func TNSNames(path string) Resolver {
+	return func(c *connection) error {
+		if !c.isForAny("oracle") || c.Host == "" || c.Port != "" || c.Database != "" || len(c.Hosts) > 0 {
+			return nil
+		}
+
+		if path == "" {
+			if path = defaultTNSNamesPath(); path == "" {
+				return nil
+			}
+		}
+
+		aliases, err := ReadTNSNames(path)
+		if err != nil {
+			return err
+		}
+
+		descriptor, ok := aliases[strings.ToLower(c.Host)]
+		if !ok {
+			return nil
+		}
+
+		resolved := &connection{}
+		if err = applyTNSDescriptor(resolved, descriptor, 0); err != nil {
+			return fmt.Errorf("tnsnames %s: alias %q: %w", path, c.Host, err)
+		}
+
+		origin := "file:" + path
+
+		c.Host, c.Hosts = resolved.Host, resolved.Hosts
+		c.setOrigin(keyHost, origin)
+
+		if resolved.Port != "" {
+			c.assign(keyPort, resolved.Port)
+			c.setOrigin(keyPort, origin)
+		}
+
+		if resolved.Database != "" {
+			c.Database = resolved.Database
+			c.setOrigin(keyDatabase, origin)
+		}
+
+		for _, property := range resolved.PropertyList() {
+			if !c.HasProperty(property.Key) {
+				c.addProperty(property.Key, property.Value, property.Raw)
+				c.setOrigin(property.Key, origin)
+			}
+		}
+
+		return nil
+	}
+}
+
+func defaultTNSNamesPath() string {
+	if dir := os.Getenv("TNS_ADMIN"); dir != "" {
+		return filepath.Join(dir, "tnsnames.ora")
+	}
+
+	if home := os.Getenv("ORACLE_HOME"); home != "" {
+		return filepath.Join(home, "network", "admin", "tnsnames.ora")
+	}
+
+	return ""
+}
+
+// ReadTNSNames reads a tnsnames.ora file into a map from each alias, in lower
+// case, to its descriptor. IFILE entries are followed, and a later definition
+// of an alias wins.
+func ReadTNSNames(path string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	if err := readTNSNames(path, aliases, make(map[string]bool), 0); err != nil {
+		return nil, err
+	}
+
+	return aliases, nil
+}
+
+func readTNSNames(path string, aliases map[string]string, visited map[string]bool, depth int) error {
+	if depth > tnsNamesMaxDepth {
+		return fmt.Errorf("tnsnames %s: too many nested IFILE entries", path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if visited[abs] {
+		return nil
+	}
+	visited[abs] = true
+
+	content, err := os.ReadFile(abs)
+	if err != nil {
+		return err
+	}
+
+	text := stripTNSComments(string(content))
+	line := func(at int) int {
+		return strings.Count(text[:at], "\n") + 1
+	}
+
+	for at := skipSpace(text, 0); at < len(text); at = skipSpace(text, at) {
+		eq := strings.IndexAny(text[at:], "=(\n")
+		if eq < 0 || text[at+eq] != '=' {
+			end := len(text)
+			if eq >= 0 {
+				end = at + eq
+			}
+
+			return fmt.Errorf("tnsnames %s:%d: expected \"=\" after %q", abs, line(at), strings.TrimSpace(text[at:end]))
+		}
+
+		names := text[at : at+eq]
+		start := skipSpace(text, at+eq+1)
+
+		var value string
+		if start < len(text) && text[start] == '(' {
+			end, err := tnsDescriptorEnd(text, start)
+			if err != nil {
+				return fmt.Errorf("tnsnames %s:%d: %w", abs, line(start), err)
+			}
+
+			value, at = text[start:end], end
+		} else {
+			end := strings.IndexByte(text[start:], '\n')
+			if end < 0 {
+				end = len(text) - start
+			}
+
+			value, at = strings.TrimSpace(text[start:start+end]), start+end
+		}
+
+		if strings.EqualFold(strings.TrimSpace(names), "ifile") {
+			if !filepath.IsAbs(value) {
+				value = filepath.Join(filepath.Dir(abs), value)
+			}
+
+			if err = readTNSNames(value, aliases, visited, depth+1); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				aliases[name] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// stripTNSComments blanks "#" comments, keeping the offsets of the rest.
+func stripTNSComments(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if at := strings.IndexByte(line, '#'); at >= 0 {
+			lines[i] = line[:at] + strings.Repeat(" ", len(line)-at)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// tnsDescriptorEnd returns the offset just past the parenthesis that closes
+// the one at start.
+func tnsDescriptorEnd(text string, start int) (int, error) {
+	depth, quoted := 0, false
+	for at := start; at < len(text); at++ {
+		switch text[at] {
+		case '"':
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				depth++
+			}
+		case ')':
+			if !quoted {
+				if depth--; depth == 0 {
+					return at + 1, nil
+				}
+			}
+		}
+	}
+
+	return 0, errors.New("unbalanced parentheses")
+}
+
+func skipSpace(text string, at int) int {
+	for at < len(text) && strings.IndexByte(" \t\r\n", text[at]) >= 0 {
+		at++
+	}
+
+	return at
+}
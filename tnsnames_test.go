@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tnsNamesFile = `# production
+ORCL, orcl.world =
+  (DESCRIPTION =
+    (ADDRESS = (PROTOCOL = TCP)(HOST = db1.example.com)(PORT = 1521))
+    (ADDRESS = (PROTOCOL = TCP)(HOST = db2.example.com)(PORT = 1521))
+    (CONNECT_DATA = (SERVICE_NAME = orcl.example.com)) # the service
+  )
+
+IFILE = extra.ora
+`
+
+func writeTNSNames(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "extra.ora"), "reports=(DESCRIPTION=(ADDRESS=(HOST=reports))(CONNECT_DATA=(SID=rep)))\nIFILE=tnsnames.ora\n")
+
+	return writeFile(t, filepath.Join(dir, "tnsnames.ora"), tnsNamesFile)
+}
+
+func TestReadTNSNames(t *testing.T) {
+	aliases, err := ReadTNSNames(writeTNSNames(t))
+
+	assert.NoError(t, err)
+	assert.Len(t, aliases, 3)
+	assert.Equal(t, aliases["orcl"], aliases["orcl.world"])
+	assert.Equal(t, "(DESCRIPTION=(ADDRESS=(HOST=reports))(CONNECT_DATA=(SID=rep)))", aliases["reports"])
+}
+
+func TestReadTNSNamesErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	checks := map[string]string{
+		"missing equals": "orcl (DESCRIPTION=(HOST=db))",
+		"unbalanced":     "orcl = (DESCRIPTION=(HOST=db)",
+	}
+
+	expected := map[string]string{
+		"missing equals": `:1: expected "=" after "orcl"`,
+		"unbalanced":     ":1: unbalanced parentheses",
+	}
+
+	for name, content := range checks {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, filepath.Join(dir, name+".ora"), content)
+
+			_, err := ReadTNSNames(path)
+
+			assert.EqualError(t, err, "tnsnames "+path+expected[name])
+		})
+	}
+
+	_, err := ReadTNSNames(filepath.Join(dir, "missing.ora"))
+	assert.Error(t, err)
+}
+
+func TestTNSNamesResolver(t *testing.T) {
+	path := writeTNSNames(t)
+	p := NewParser(WithResolvers(TNSNames(path)))
+
+	conn, err := p.ParseAs(FormatEZConnect, "scott/tiger@ORCL")
+
+	assert.NoError(t, err)
+	assertConnection(t, &connection{
+		Type:        toPtr("oracle"),
+		Username:    toPtr("scott"),
+		Password:    toPtr("tiger"),
+		Host:        "db1.example.com",
+		Port:        "1521",
+		NumericPort: 1521,
+		Hosts: []Endpoint{
+			{Host: "db1.example.com", Port: "1521"},
+			{Host: "db2.example.com", Port: "1521"},
+		},
+		Database: "orcl.example.com",
+		Properties: map[string][]string{
+			"protocol": {"TCP"},
+		},
+		origins: map[string]string{
+			"host":     "file:" + path,
+			"port":     "file:" + path,
+			"database": "file:" + path,
+			"protocol": "file:" + path,
+		},
+	}, conn)
+
+	conn, err = p.ParseAs(FormatEZConnect, "reports")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "reports", conn.Host)
+	assert.Equal(t, "rep", conn.GetProperty("sid"))
+
+	// a full address is left alone, and so is an unknown alias
+	conn, err = p.ParseAs(FormatEZConnect, "orcl:1521/other")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "orcl", conn.Host)
+	assert.Equal(t, "other", conn.Database)
+
+	conn, err = p.ParseAs(FormatEZConnect, "unknown")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "unknown", conn.Host)
+}
+
+func TestTNSNamesResolverDefaultPath(t *testing.T) {
+	path := writeTNSNames(t)
+
+	t.Setenv("TNS_ADMIN", filepath.Dir(path))
+
+	conn, err := NewParser(WithResolvers(TNSNames(""))).ParseAs(FormatEZConnect, "orcl")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "db1.example.com", conn.Host)
+
+	t.Setenv("TNS_ADMIN", "")
+	t.Setenv("ORACLE_HOME", "")
+
+	conn, err = NewParser(WithResolvers(TNSNames(""))).ParseAs(FormatEZConnect, "orcl")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "orcl", conn.Host)
+}
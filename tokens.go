@@ -0,0 +1,529 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type TokenKind string
+
+const TokenScheme TokenKind = "scheme"
+const TokenSeparator TokenKind = "separator"
+const TokenUser TokenKind = "user"
+const TokenPassword TokenKind = "password"
+const TokenHost TokenKind = "host"
+const TokenPort TokenKind = "port"
+const TokenDatabase TokenKind = "database"
+const TokenKey TokenKind = "key"
+const TokenValue TokenKind = "value"
+const TokenQuote TokenKind = "quote"
+const TokenError TokenKind = "error"
+
+// Token is a piece of the input as written. Message explains an error token.
+type Token struct {
+	Kind    TokenKind
+	Text    string
+	Span    Span
+	Message string
+}
+
+type tokenizer struct {
+	input  string
+	tokens []Token
+}
+
+// Tokenize splits the input into tokens that cover every byte of it, for
+// syntax highlighting. Problems in the input become error tokens rather than
+// an error.
+func (p *parser) Tokenize(input string) ([]Token, error) {
+	return p.TokenizeAs(p.Detect(input), input)
+}
+
+func (p *parser) TokenizeAs(name Format, input string) ([]Token, error) {
+	t := &tokenizer{input: input}
+
+	switch name {
+	case FormatURL:
+		t.url(p)
+	case FormatPairs:
+		t.pairs(p, p.canonical)
+	case FormatADONET:
+		t.pairs(p.adoNetParser(), adoNetKey)
+	case FormatEZConnect:
+		t.ezConnect(p)
+	case FormatHostList:
+		t.hostList(p)
+	case FormatPDO:
+		t.pdo(p)
+	default:
+		return nil, fmt.Errorf("format %q has no tokenizer", name)
+	}
+
+	return t.finish(), nil
+}
+
+func Tokenize(input string) ([]Token, error) {
+	return defaultParser.Tokenize(input)
+}
+
+func TokenizeAs(name Format, input string) ([]Token, error) {
+	return defaultParser.TokenizeAs(name, input)
+}
+
+func (t *tokenizer) add(kind TokenKind, span Span, message ...string) {
+	if span.Start >= span.End {
+		return
+	}
+
+	token := Token{Kind: kind, Span: span}
+	if len(message) > 0 {
+		token.Message = message[0]
+	}
+
+	t.tokens = append(t.tokens, token)
+}
+
+// finish orders the tokens, joins the neighbours of one kind, and fills the
+// gaps with separators. An error token stands for the tokens it overlaps.
+func (t *tokenizer) finish() []Token {
+	var failed []Span
+	for _, token := range t.tokens {
+		if token.Kind == TokenError {
+			failed = append(failed, token.Span)
+		}
+	}
+
+	kept := t.tokens[:0]
+	for _, token := range t.tokens {
+		if token.Kind == TokenError || !overlaps(token.Span, failed) {
+			kept = append(kept, token)
+		}
+	}
+
+	t.tokens = kept
+	sort.SliceStable(t.tokens, func(i, j int) bool {
+		return t.tokens[i].Span.Start < t.tokens[j].Span.Start
+	})
+
+	var tokens []Token
+	at := 0
+
+	for _, token := range t.tokens {
+		if token.Span.Start < at {
+			continue
+		}
+
+		if token.Span.Start > at {
+			tokens = append(tokens, Token{Kind: TokenSeparator, Span: Span{Start: at, End: token.Span.Start}})
+		}
+
+		if n := len(tokens); n > 0 && tokens[n-1].Kind == token.Kind && joinable(token.Kind) {
+			tokens[n-1].Span.End = token.Span.End
+		} else {
+			tokens = append(tokens, token)
+		}
+
+		at = token.Span.End
+	}
+
+	if at < len(t.input) {
+		tokens = append(tokens, Token{Kind: TokenSeparator, Span: Span{Start: at, End: len(t.input)}})
+	}
+
+	for i := range tokens {
+		tokens[i].Text = t.input[tokens[i].Span.Start:tokens[i].Span.End]
+	}
+
+	return tokens
+}
+
+func joinable(kind TokenKind) bool {
+	return kind != TokenQuote && kind != TokenError
+}
+
+func overlaps(span Span, spans []Span) bool {
+	for _, other := range spans {
+		if span.Start < other.End && other.Start < span.End {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check adds an error token for the error of the regular parse, unless a
+// token already explains it. A syntax error covers the rest of the input.
+func (t *tokenizer) check(_ *connection, err error) {
+	if err == nil || t.failed() {
+		return
+	}
+
+	span, message := Span{Start: 0, End: len(t.input)}, err.Error()
+
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) && syntaxErr.Offset < len(t.input) {
+		span.Start, message = syntaxErr.Offset, syntaxErr.Message
+	}
+
+	t.add(TokenError, span, message)
+}
+
+// url tokenizes with the spans of the lossless scanner, and leaves the errors
+// to the regular parse.
+func (t *tokenizer) url(p *parser) {
+	d := &Document{parser: p, format: FormatURL, input: t.input}
+	d.scanURL()
+
+	for _, node := range d.nodes {
+		switch node.Kind {
+		case NodeScheme:
+			t.add(TokenScheme, node.Span)
+		case NodeUsername:
+			t.escaped(TokenUser, node.Span, url.PathUnescape)
+		case NodePassword:
+			t.escaped(TokenPassword, node.Span, url.PathUnescape)
+		case NodeHost:
+			if d.url.list {
+				t.addresses(node.Span, cutPort)
+			} else {
+				t.add(TokenHost, node.Span)
+			}
+		case NodePort:
+			t.port(node.Span)
+		case NodePath:
+			// the leading slashes separate the path from the authority, but
+			// the path of a file URL keeps its root
+			path := d.text(node.Span)
+			span := Span{Start: node.Span.End - len(strings.TrimLeft(path, "/")), End: node.Span.End}
+			if d.url.file {
+				span.Start = node.Span.Start + len(path) - len(strings.TrimPrefix(path, "//"))
+			}
+			t.escaped(TokenDatabase, span, url.PathUnescape)
+		}
+	}
+
+	if d.url.query != nil {
+		t.query(*d.url.query)
+	}
+
+	t.check(p.fromUrl(t.input))
+}
+
+func (t *tokenizer) escaped(kind TokenKind, span Span, unescape func(string) (string, error)) {
+	if _, err := unescape(t.input[span.Start:span.End]); err != nil {
+		t.add(TokenError, span, err.Error())
+		return
+	}
+
+	t.add(kind, span)
+}
+
+func (t *tokenizer) port(span Span) {
+	if port := t.input[span.Start:span.End]; !isDigits(port) {
+		t.add(TokenError, span, fmt.Sprintf("port %q is not a number", port))
+		return
+	}
+
+	t.add(TokenPort, span)
+}
+
+// query flags the pairs that url.Query skips.
+func (t *tokenizer) query(query Span) {
+	scanQuery(t.input[query.Start:query.End], func(pair queryPair) {
+		start := query.Start + pair.offset
+		if pair.err != nil {
+			t.add(TokenError, Span{Start: start, End: start + len(pair.text)}, pair.err.Error())
+			return
+		}
+
+		t.add(TokenKey, Span{Start: start, End: start + len(pair.raw)})
+		t.add(TokenValue, Span{Start: start + len(pair.raw) + 1, End: start + len(pair.text)})
+	})
+}
+
+// addresses splits a list of addresses with the parser's own cut. In a list of
+// URLs, the ones after the first repeat the scheme and may repeat the user info.
+func (t *tokenizer) addresses(list Span, cut func(string) (string, string, bool)) {
+	start := list.Start
+	for _, address := range strings.Split(t.input[list.Start:list.End], ",") {
+		span := Span{Start: start, End: start + len(address)}
+		start = span.End + 1
+
+		if prefix := urlSchemePattern.FindString(address); prefix != "" {
+			t.add(TokenScheme, Span{Start: span.Start, End: span.Start + len(prefix) - len("://")})
+			span.Start += len(prefix)
+
+			if at := strings.LastIndexByte(t.input[span.Start:span.End], '@'); at >= 0 {
+				t.userinfo(Span{Start: span.Start, End: span.Start + at})
+				span.Start += at + 1
+			}
+		}
+
+		t.address(span, cut)
+	}
+}
+
+func (t *tokenizer) userinfo(span Span) {
+	username := span
+	if colon := strings.IndexByte(t.input[span.Start:span.End], ':'); colon >= 0 {
+		username.End = span.Start + colon
+		t.escaped(TokenPassword, Span{Start: username.End + 1, End: span.End}, url.PathUnescape)
+	}
+
+	t.escaped(TokenUser, username, url.PathUnescape)
+}
+
+func (t *tokenizer) address(span Span, cut func(string) (string, string, bool)) {
+	text := t.input[span.Start:span.End]
+	trimmed := strings.TrimSpace(text)
+	span.Start += strings.Index(text, trimmed)
+	span.End = span.Start + len(trimmed)
+
+	host, _, hasPort := cut(trimmed)
+	hostSpan := Span{Start: span.Start, End: span.Start + len(host)}
+	if trimBrackets(host) != host {
+		hostSpan = Span{Start: hostSpan.Start + 1, End: hostSpan.End - 1}
+	}
+
+	t.add(TokenHost, hostSpan)
+	if hasPort {
+		t.port(Span{Start: span.Start + len(host) + 1, End: span.End})
+	}
+}
+
+// quoted adds a token of an Oracle name, which may be wrapped in double quotes.
+func (t *tokenizer) quoted(kind TokenKind, span Span) {
+	text := t.input[span.Start:span.End]
+	if len(text) < 2 || text[0] != '"' || text[len(text)-1] != '"' {
+		t.add(kind, span)
+		return
+	}
+
+	t.add(TokenQuote, Span{Start: span.Start, End: span.Start + 1})
+	t.add(kind, Span{Start: span.Start + 1, End: span.End - 1})
+	t.add(TokenQuote, Span{Start: span.End - 1, End: span.End})
+}
+
+// ezConnect follows fromEZConnect: user/password@//host:port/service:server/instance?key=value.
+func (t *tokenizer) ezConnect(p *parser) {
+	credentials, rest, offset := cutOracleCredentials(t.input)
+	if offset > 0 {
+		username, _, hasPassword := strings.Cut(credentials, "/")
+		t.quoted(TokenUser, Span{Start: 0, End: len(username)})
+		if hasPassword {
+			t.quoted(TokenPassword, Span{Start: len(username) + 1, End: offset - 1})
+		}
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(rest), "(") {
+		// a TNS descriptor has no tokenizer of its own
+		t.check(p.fromEZConnect(t.input))
+		return
+	}
+
+	if protocol, after, ok := strings.Cut(rest, "://"); ok && !strings.ContainsAny(protocol, "/:@") {
+		t.add(TokenScheme, Span{Start: offset, End: offset + len(protocol)})
+		rest, offset = after, offset+len(protocol)+len("://")
+	} else if strings.HasPrefix(rest, "//") {
+		rest, offset = rest[2:], offset+2
+	}
+
+	target, _, hasQuery := strings.Cut(rest, "?")
+	addresses, path, hasPath := strings.Cut(target, "/")
+
+	start := offset
+	for _, list := range strings.Split(addresses, ";") {
+		t.addresses(Span{Start: start, End: start + len(list)}, cutOraclePort)
+		start += len(list) + 1
+	}
+
+	if hasPath {
+		at := offset + len(addresses) + 1
+		service, _, hasInstance := strings.Cut(path, "/")
+		name, _, hasServer := strings.Cut(service, ":")
+
+		t.add(TokenDatabase, Span{Start: at, End: at + len(name)})
+		if hasServer {
+			t.add(TokenValue, Span{Start: at + len(name) + 1, End: at + len(service)})
+		}
+
+		if hasInstance {
+			t.add(TokenValue, Span{Start: at + len(service) + 1, End: at + len(path)})
+		}
+	}
+
+	if hasQuery {
+		t.query(Span{Start: offset + len(target) + 1, End: len(t.input)})
+	}
+
+	t.check(p.fromEZConnect(t.input))
+}
+
+func (t *tokenizer) hostList(p *parser) {
+	t.addresses(Span{Start: 0, End: len(t.input)}, cutPort)
+	t.check(p.fromHostList(t.input))
+}
+
+// pdo follows fromPDO: driver:key=value;key=value.
+func (t *tokenizer) pdo(p *parser) {
+	driver, _, ok := strings.Cut(t.input, ":")
+	if ok && strings.EqualFold(driver, "sqlite") {
+		t.url(p)
+		return
+	}
+
+	if !ok {
+		t.check(p.fromPDO(t.input))
+		return
+	}
+
+	t.add(TokenScheme, Span{Start: 0, End: len(driver)})
+
+	// a scratch connection finds the pairs the parser rejects
+	c := &connection{}
+	_ = scanPDO(t.input, strings.ToLower(driver), func(offset int, field string) error {
+		if err := p.assignPDO(c, offset, field); err != nil {
+			var syntaxErr *SyntaxError
+			if errors.As(err, &syntaxErr) {
+				t.add(TokenError, Span{Start: offset, End: offset + len(field)}, syntaxErr.Message)
+			}
+
+			return nil
+		}
+
+		key, value, separated := strings.Cut(field, "=")
+		if !separated {
+			return nil
+		}
+
+		name, trimmed := strings.TrimSpace(key), strings.TrimSpace(value)
+		keyStart := offset + strings.Index(key, name)
+		valueStart := offset + len(key) + 1 + strings.Index(value, trimmed)
+
+		t.add(TokenKey, Span{Start: keyStart, End: keyStart + len(name)})
+		t.add(valueToken(p.canonical(pdoKey(name))), Span{Start: valueStart, End: valueStart + len(trimmed)})
+
+		return nil
+	})
+}
+
+func (t *tokenizer) failed() bool {
+	for _, token := range t.tokens {
+		if token.Kind == TokenError {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *tokenizer) pairs(p *parser, key func(string) string) {
+	fields, err := p.lexPairs(t.input)
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.add(TokenError, Span{Start: 0, End: len(t.input)}, err.Error())
+			return
+		}
+
+		// the text before the problem lexes on its own
+		fields, _ = p.lexPairs(t.input[:syntaxErr.Offset])
+		t.add(TokenError, Span{Start: syntaxErr.Offset, End: len(t.input)}, syntaxErr.Message)
+	}
+
+	// a scratch connection finds the pairs that strict mode rejects
+	c := &connection{}
+
+	for _, field := range fields {
+		if field.text() == "" {
+			continue
+		}
+
+		span := Span{Start: field.start, End: field.end}
+		k, v, separated := field.split(p.separator)
+		if !separated && p.strict {
+			t.add(TokenError, span, fmt.Sprintf("missing %q in %q", p.separator, field.text()))
+			continue
+		}
+
+		shared := separated && v.start < k.end
+		keyEnd := k.end
+
+		k = newPairField(trimSegments(k.segments))
+		if p.trimValues {
+			v.segments = trimSegments(v.segments)
+		}
+
+		name := key(strings.TrimSpace(k.text()))
+		if _, err := p.assign(c, name, v.text()); err != nil {
+			t.add(TokenError, span, err.Error())
+			continue
+		}
+
+		kind := valueToken(p.canonical(name))
+		if !shared {
+			t.segments(p, TokenKey, k.segments)
+			t.segments(p, kind, v.segments)
+			continue
+		}
+
+		// the separator sits inside a quoted segment both halves share
+		last := len(k.segments) - 1
+		t.segments(p, TokenKey, k.segments[:last])
+		t.sharedQuote(p, k.segments[last], keyEnd, kind)
+		t.segments(p, kind, v.segments[1:])
+	}
+}
+
+func (t *tokenizer) segments(p *parser, kind TokenKind, segments []segment) {
+	for _, s := range segments {
+		quote, size := utf8.DecodeRuneInString(t.input[s.start:])
+		if !s.quoted || !p.isQuote(quote) || s.end-s.start < 2*size {
+			t.add(kind, Span{Start: s.start, End: s.end})
+			continue
+		}
+
+		t.add(TokenQuote, Span{Start: s.start, End: s.start + size})
+		t.add(kind, Span{Start: s.start + size, End: s.end - size})
+		t.add(TokenQuote, Span{Start: s.end - size, End: s.end})
+	}
+}
+
+func (t *tokenizer) sharedQuote(p *parser, s segment, end int, kind TokenKind) {
+	_, size := utf8.DecodeRuneInString(t.input[s.start:])
+	inner := Span{Start: s.start + size, End: end - size}
+
+	at := strings.Index(t.input[inner.Start:inner.End], p.separator)
+	if at < 0 {
+		t.segments(p, TokenKey, []segment{s})
+		return
+	}
+
+	t.add(TokenQuote, Span{Start: s.start, End: inner.Start})
+	t.add(TokenKey, Span{Start: inner.Start, End: inner.Start + at})
+	t.add(kind, Span{Start: inner.Start + at + len(p.separator), End: inner.End})
+	t.add(TokenQuote, Span{Start: inner.End, End: end})
+}
+
+func valueToken(key string) TokenKind {
+	switch key {
+	case keyType:
+		return TokenScheme
+	case keyUsername:
+		return TokenUser
+	case keyPassword:
+		return TokenPassword
+	case keyHost:
+		return TokenHost
+	case keyPort:
+		return TokenPort
+	case keyDatabase:
+		return TokenDatabase
+	}
+
+	return TokenValue
+}
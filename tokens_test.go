@@ -0,0 +1,325 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenCheck struct {
+	kind    TokenKind
+	text    string
+	message string
+}
+
+func tokenChecks(tokens []Token) []tokenCheck {
+	var checks []tokenCheck
+	for _, token := range tokens {
+		checks = append(checks, tokenCheck{kind: token.Kind, text: token.Text, message: token.Message})
+	}
+
+	return checks
+}
+
+func TestTokenize(t *testing.T) {
+	checks := map[string]struct {
+		parser   *parser
+		input    string
+		expected []tokenCheck
+	}{
+		"url": {
+			parser: NewParser(),
+			input:  "postgres://alice:s%40cret@[::1]:5432/app?sslmode=require&flag#top",
+			expected: []tokenCheck{
+				{kind: TokenScheme, text: "postgres"},
+				{kind: TokenSeparator, text: "://"},
+				{kind: TokenUser, text: "alice"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenPassword, text: "s%40cret"},
+				{kind: TokenSeparator, text: "@["},
+				{kind: TokenHost, text: "::1"},
+				{kind: TokenSeparator, text: "]:"},
+				{kind: TokenPort, text: "5432"},
+				{kind: TokenSeparator, text: "/"},
+				{kind: TokenDatabase, text: "app"},
+				{kind: TokenSeparator, text: "?"},
+				{kind: TokenKey, text: "sslmode"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenValue, text: "require"},
+				{kind: TokenSeparator, text: "&"},
+				{kind: TokenKey, text: "flag"},
+				{kind: TokenSeparator, text: "#top"},
+			},
+		},
+		"url - problems": {
+			parser: NewParser(),
+			input:  "mysql://root:%zz@db:33x6/app?a=1&b;c=2",
+			expected: []tokenCheck{
+				{kind: TokenScheme, text: "mysql"},
+				{kind: TokenSeparator, text: "://"},
+				{kind: TokenUser, text: "root"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenError, text: "%zz", message: `invalid URL escape "%zz"`},
+				{kind: TokenSeparator, text: "@"},
+				{kind: TokenHost, text: "db"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenError, text: "33x6", message: `port "33x6" is not a number`},
+				{kind: TokenSeparator, text: "/"},
+				{kind: TokenDatabase, text: "app"},
+				{kind: TokenSeparator, text: "?"},
+				{kind: TokenKey, text: "a"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenValue, text: "1"},
+				{kind: TokenSeparator, text: "&"},
+				{kind: TokenError, text: "b;c=2", message: "invalid semicolon separator in query"},
+			},
+		},
+		"url - undecodable query pair": {
+			parser: NewParser(),
+			input:  "postgres://db/app?b=%zz&c=1",
+			expected: []tokenCheck{
+				{kind: TokenScheme, text: "postgres"},
+				{kind: TokenSeparator, text: "://"},
+				{kind: TokenHost, text: "db"},
+				{kind: TokenSeparator, text: "/"},
+				{kind: TokenDatabase, text: "app"},
+				{kind: TokenSeparator, text: "?"},
+				{kind: TokenError, text: "b=%zz", message: `invalid URL escape "%zz"`},
+				{kind: TokenSeparator, text: "&"},
+				{kind: TokenKey, text: "c"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenValue, text: "1"},
+			},
+		},
+		"url - hosts and socket": {
+			parser: NewParser(),
+			input:  "mongodb://h1:1,[::1]:x/app",
+			expected: []tokenCheck{
+				{kind: TokenScheme, text: "mongodb"},
+				{kind: TokenSeparator, text: "://"},
+				{kind: TokenHost, text: "h1"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenPort, text: "1"},
+				{kind: TokenSeparator, text: ",["},
+				{kind: TokenHost, text: "::1"},
+				{kind: TokenSeparator, text: "]:"},
+				{kind: TokenError, text: "x", message: `port "x" is not a number`},
+				{kind: TokenSeparator, text: "/"},
+				{kind: TokenDatabase, text: "app"},
+			},
+		},
+		"url - list of urls": {
+			parser: NewParser(),
+			input:  "nats://alice@n1:4222,nats://n2:4222",
+			expected: []tokenCheck{
+				{kind: TokenScheme, text: "nats"},
+				{kind: TokenSeparator, text: "://"},
+				{kind: TokenUser, text: "alice"},
+				{kind: TokenSeparator, text: "@"},
+				{kind: TokenHost, text: "n1"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenPort, text: "4222"},
+				{kind: TokenSeparator, text: ","},
+				{kind: TokenScheme, text: "nats"},
+				{kind: TokenSeparator, text: "://"},
+				{kind: TokenHost, text: "n2"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenPort, text: "4222"},
+			},
+		},
+		"url - socket": {
+			parser: NewParser(),
+			input:  "postgres://%2Ftmp%2Fpg/app",
+			expected: []tokenCheck{
+				{kind: TokenScheme, text: "postgres"},
+				{kind: TokenSeparator, text: "://"},
+				{kind: TokenHost, text: "%2Ftmp%2Fpg"},
+				{kind: TokenSeparator, text: "/"},
+				{kind: TokenDatabase, text: "app"},
+			},
+		},
+		"pairs": {
+			parser: NewParser(),
+			input:  `host=db.example.com  password="a b" "sslmode=require" flag`,
+			expected: []tokenCheck{
+				{kind: TokenKey, text: "host"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenHost, text: "db.example.com"},
+				{kind: TokenSeparator, text: "  "},
+				{kind: TokenKey, text: "password"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenQuote, text: `"`},
+				{kind: TokenPassword, text: "a b"},
+				{kind: TokenQuote, text: `"`},
+				{kind: TokenSeparator, text: " "},
+				{kind: TokenQuote, text: `"`},
+				{kind: TokenKey, text: "sslmode"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenValue, text: "require"},
+				{kind: TokenQuote, text: `"`},
+				{kind: TokenSeparator, text: " "},
+				{kind: TokenKey, text: "flag"},
+			},
+		},
+		"pairs - escapes and trimmed values": {
+			parser: NewParser(WithDelimiter(';'), WithEscape('\\'), WithTrimValues(true)),
+			input:  `user = al\;ice ; port=5432`,
+			expected: []tokenCheck{
+				{kind: TokenKey, text: "user"},
+				{kind: TokenSeparator, text: " = "},
+				{kind: TokenUser, text: `al\;ice`},
+				{kind: TokenSeparator, text: " ; "},
+				{kind: TokenKey, text: "port"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenPort, text: "5432"},
+			},
+		},
+		"pairs - unterminated quote": {
+			parser: NewParser(),
+			input:  `host=db password="secret`,
+			expected: []tokenCheck{
+				{kind: TokenKey, text: "host"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenHost, text: "db"},
+				{kind: TokenSeparator, text: " "},
+				{kind: TokenKey, text: "password"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenError, text: `"secret`, message: `unterminated " quote`},
+			},
+		},
+		"pairs - strict": {
+			parser: NewParser(WithStrict(true)),
+			input:  "host=a port=x host=b flag",
+			expected: []tokenCheck{
+				{kind: TokenKey, text: "host"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenHost, text: "a"},
+				{kind: TokenSeparator, text: " "},
+				{kind: TokenError, text: "port=x", message: `port "x" is not a number`},
+				{kind: TokenSeparator, text: " "},
+				{kind: TokenError, text: "host=b", message: "host is set more than once"},
+				{kind: TokenSeparator, text: " "},
+				{kind: TokenError, text: "flag", message: `missing "=" in "flag"`},
+			},
+		},
+		"adonet": {
+			parser: NewParser(),
+			input:  "Server=db;Initial Catalog='app';Encrypt=yes",
+			expected: []tokenCheck{
+				{kind: TokenKey, text: "Server"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenHost, text: "db"},
+				{kind: TokenSeparator, text: ";"},
+				{kind: TokenKey, text: "Initial Catalog"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenQuote, text: "'"},
+				{kind: TokenDatabase, text: "app"},
+				{kind: TokenQuote, text: "'"},
+				{kind: TokenSeparator, text: ";"},
+				{kind: TokenKey, text: "Encrypt"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenValue, text: "yes"},
+			},
+		},
+		"ezconnect": {
+			parser: NewParser(),
+			input:  `scott/"ti@ger"@db:1521,db2/svc:dedicated?connect_timeout=5`,
+			expected: []tokenCheck{
+				{kind: TokenUser, text: "scott"},
+				{kind: TokenSeparator, text: "/"},
+				{kind: TokenQuote, text: `"`},
+				{kind: TokenPassword, text: "ti@ger"},
+				{kind: TokenQuote, text: `"`},
+				{kind: TokenSeparator, text: "@"},
+				{kind: TokenHost, text: "db"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenPort, text: "1521"},
+				{kind: TokenSeparator, text: ","},
+				{kind: TokenHost, text: "db2"},
+				{kind: TokenSeparator, text: "/"},
+				{kind: TokenDatabase, text: "svc"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenValue, text: "dedicated"},
+				{kind: TokenSeparator, text: "?"},
+				{kind: TokenKey, text: "connect_timeout"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenValue, text: "5"},
+			},
+		},
+		"host list": {
+			parser: NewParser(),
+			input:  "b1:9092, [::1]:9093",
+			expected: []tokenCheck{
+				{kind: TokenHost, text: "b1"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenPort, text: "9092"},
+				{kind: TokenSeparator, text: ", ["},
+				{kind: TokenHost, text: "::1"},
+				{kind: TokenSeparator, text: "]:"},
+				{kind: TokenPort, text: "9093"},
+			},
+		},
+		"pdo": {
+			parser: NewParser(),
+			input:  "mysql:host=db; dbname = app;=x",
+			expected: []tokenCheck{
+				{kind: TokenScheme, text: "mysql"},
+				{kind: TokenSeparator, text: ":"},
+				{kind: TokenKey, text: "host"},
+				{kind: TokenSeparator, text: "="},
+				{kind: TokenHost, text: "db"},
+				{kind: TokenSeparator, text: "; "},
+				{kind: TokenKey, text: "dbname"},
+				{kind: TokenSeparator, text: " = "},
+				{kind: TokenDatabase, text: "app"},
+				{kind: TokenSeparator, text: ";"},
+				{kind: TokenError, text: "=x", message: "attribute has no key"},
+			},
+		},
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			tokens, err := check.parser.Tokenize(check.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, check.expected, tokenChecks(tokens))
+
+			// the tokens cover the input, in order
+			var b strings.Builder
+			at := 0
+			for _, token := range tokens {
+				assert.Equal(t, at, token.Span.Start)
+				assert.Equal(t, check.input[token.Span.Start:token.Span.End], token.Text)
+				b.WriteString(token.Text)
+				at = token.Span.End
+			}
+
+			assert.Equal(t, check.input, b.String())
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tokens, err := NewParser(WithKeyValueSeparator("")).Tokenize("host=db")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Token{
+		{Kind: TokenError, Text: "host=db", Span: Span{Start: 0, End: 7}, Message: errInvalidSeparator.Error()},
+	}, tokens)
+
+	tokens, err = TokenizeAs(FormatURL, "postgres://[::1/app")
+
+	assert.NoError(t, err)
+	assert.Equal(t, TokenError, tokens[0].Kind)
+	assert.Len(t, tokens, 1)
+
+	_, err = TokenizeAs(FormatJDBC, "jdbc:postgresql://db/app")
+	assert.EqualError(t, err, `format "jdbc" has no tokenizer`)
+
+	tokens, err = Tokenize("")
+
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+}